@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jshawl/dbq/internal/config"
+	"github.com/jshawl/dbq/internal/db"
+	"github.com/jshawl/dbq/internal/format"
+)
+
+// Options configure a non-interactive run.
+type Options struct {
+	// Profile names a profile in the config at ConfigPath. When empty,
+	// DatabaseURL or the config's default profile is used, as in the ui.
+	Profile     string
+	DatabaseURL string
+	ConfigPath  string
+	SQL         string
+	Format      string
+	// ReadOnly connects read-only even when the profile does not ask to.
+	ReadOnly bool
+}
+
+const (
+	ExitOK         = 0
+	ExitQueryError = 1
+	ExitUsageError = 2
+)
+
+// Run executes options.SQL, writing each statement's results to stdout
+// and errors to stderr, and returns the process's exit code. Statements
+// stop at the first error.
+func Run(ctx context.Context, options Options, stdout io.Writer, stderr io.Writer) int {
+	outputFormat, err := format.Parse(options.Format)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitUsageError
+	}
+
+	cfg, err := config.Load(options.ConfigPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitUsageError
+	}
+
+	profile, err := cfg.Resolve(options.Profile, options.DatabaseURL)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitUsageError
+	}
+
+	queryable, err := db.Open(ctx, profile.DSN, db.Options{
+		Schema:   profile.Schema,
+		ReadOnly: profile.ReadOnly || options.ReadOnly,
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitUsageError
+	}
+
+	database := db.NewDB(queryable)
+
+	defer func() {
+		_ = database.Close(context.Background())
+	}()
+
+	for _, statement := range database.QueryStatements(ctx, options.SQL) {
+		if statement.Err != nil {
+			fmt.Fprintln(stderr, "ERROR:", statement.Err)
+
+			return ExitQueryError
+		}
+
+		err := write(stdout, outputFormat, statement.Results)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+
+			return ExitQueryError
+		}
+	}
+
+	return ExitOK
+}
+
+// write prints a statement's results. Statements without a result set
+// print their command tag in the table format, like psql, and nothing in
+// the machine readable ones.
+func write(w io.Writer, outputFormat format.Format, results db.QueryResult) error {
+	if len(results.Columns) == 0 {
+		if outputFormat != format.FormatTable {
+			return nil
+		}
+
+		_, err := fmt.Fprintln(w, results.CommandTag)
+		if err != nil {
+			return fmt.Errorf("%w: %w", format.ErrWrite, err)
+		}
+
+		return nil
+	}
+
+	err := format.Write(w, outputFormat, results)
+	if err != nil || outputFormat != format.FormatTable {
+		return err //nolint:wrapcheck // already wrapped by format
+	}
+
+	_, err = fmt.Fprintf(w, "(%s)\n\n", format.RowCount(len(results.Rows)))
+	if err != nil {
+		return fmt.Errorf("%w: %w", format.ErrWrite, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package cli_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/cli"
+)
+
+func run(t *testing.T, sql string, outputFormat string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr strings.Builder
+
+	code := cli.Run(t.Context(), cli.Options{
+		Profile:     "",
+		DatabaseURL: "sqlite://" + filepath.Join(t.TempDir(), "dbq_test.sqlite3"),
+		ConfigPath:  filepath.Join(t.TempDir(), "config.yaml"),
+		SQL:         sql,
+		Format:      outputFormat,
+		ReadOnly:    false,
+	}, &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+const setupSQL = `create table users (id integer primary key, name text);
+insert into users (name) values ('Jane'), ('John');
+`
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+
+		code, stdout, _ := run(t, setupSQL+"select * from users order by id", "table")
+		if code != cli.ExitOK {
+			t.Fatalf("expected exit code %d, got %d", cli.ExitOK, code)
+		}
+
+		want := strings.Join([]string{
+			"CREATE TABLE",
+			"INSERT 0 2",
+			" id | name",
+			"----+------",
+			"  1 | Jane",
+			"  2 | John",
+			"(2 rows)",
+			"",
+			"",
+		}, "\n")
+		if stdout != want {
+			t.Fatalf("expected:\n%s\ngot:\n%s", want, stdout)
+		}
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		t.Parallel()
+
+		code, stdout, _ := run(t, setupSQL+"select * from users order by id", "ndjson")
+		if code != cli.ExitOK {
+			t.Fatalf("expected exit code %d, got %d", cli.ExitOK, code)
+		}
+
+		want := `{"id":1,"name":"Jane"}` + "\n" + `{"id":2,"name":"John"}` + "\n"
+		if stdout != want {
+			t.Fatalf("expected:\n%s\ngot:\n%s", want, stdout)
+		}
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		t.Parallel()
+
+		code, stdout, stderr := run(t, "select * from missing; select 1", "csv")
+		if code != cli.ExitQueryError {
+			t.Fatalf("expected exit code %d, got %d", cli.ExitQueryError, code)
+		}
+
+		if stdout != "" || !strings.Contains(stderr, "no such table: missing") {
+			t.Fatalf("expected error on stderr only, got stdout %q stderr %q", stdout, stderr)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		t.Parallel()
+
+		code, _, stderr := run(t, "select 1", "xml")
+		if code != cli.ExitUsageError || !strings.Contains(stderr, "unknown format") {
+			t.Fatalf("expected usage error, got %d %q", code, stderr)
+		}
+	})
+	t.Run("read only", func(t *testing.T) {
+		t.Parallel()
+
+		options := cli.Options{
+			Profile:     "",
+			DatabaseURL: "sqlite://" + filepath.Join(t.TempDir(), "dbq_test.sqlite3"),
+			ConfigPath:  filepath.Join(t.TempDir(), "config.yaml"),
+			SQL:         setupSQL,
+			Format:      "table",
+			ReadOnly:    false,
+		}
+
+		var stdout, stderr strings.Builder
+		if code := cli.Run(t.Context(), options, &stdout, &stderr); code != cli.ExitOK {
+			t.Fatalf("expected exit code %d, got %d: %s", cli.ExitOK, code, stderr.String())
+		}
+
+		options.ReadOnly = true
+		options.SQL = "select count(*) from users; delete from users"
+		stderr.Reset()
+
+		code := cli.Run(t.Context(), options, &stdout, &stderr)
+		if code != cli.ExitQueryError || !strings.Contains(stderr.String(), "readonly") {
+			t.Fatalf("expected the delete to be refused, got %d %q", code, stderr.String())
+		}
+	})
+}
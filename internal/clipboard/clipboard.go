@@ -0,0 +1,66 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+var ErrCopy = errors.New("failed to copy to clipboard")
+
+// Write puts text on the clipboard. Over SSH the system clipboard belongs
+// to the remote box, so text is sent to the local terminal as an OSC52
+// escape sequence instead, which is also the fallback when no clipboard
+// utility is installed. The sequence is written to the terminal in one
+// write, so it is not split by the ui's own output or lost when stderr
+// is redirected.
+func Write(text string) error {
+	if !overSSH() && clipboard.WriteAll(text) == nil {
+		return nil
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCopy, err)
+	}
+
+	err = WriteOSC52(tty, text)
+
+	closeErr := tty.Close()
+	if err == nil && closeErr != nil {
+		return fmt.Errorf("%w: %w", ErrCopy, closeErr)
+	}
+
+	return err
+}
+
+// WriteOSC52 writes the OSC52 sequence that copies text, wrapped for tmux
+// or screen when running inside them.
+func WriteOSC52(w io.Writer, text string) error {
+	sequence := osc52.New(text)
+
+	term := os.Getenv("TERM")
+
+	switch {
+	case os.Getenv("TMUX") != "" || strings.HasPrefix(term, "tmux"):
+		sequence = sequence.Tmux()
+	case strings.HasPrefix(term, "screen"):
+		sequence = sequence.Screen()
+	}
+
+	_, err := sequence.WriteTo(w)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCopy, err)
+	}
+
+	return nil
+}
+
+func overSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
@@ -0,0 +1,45 @@
+package clipboard_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/clipboard"
+)
+
+func TestWriteOSC52(t *testing.T) {
+	t.Run("terminal", func(t *testing.T) {
+		t.Setenv("TERM", "xterm-256color")
+		t.Setenv("TMUX", "")
+
+		var buffer bytes.Buffer
+
+		err := clipboard.WriteOSC52(&buffer, "hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("hello")) + "\x07"
+		if buffer.String() != want {
+			t.Fatalf("expected %q, got %q", want, buffer.String())
+		}
+	})
+
+	t.Run("tmux", func(t *testing.T) {
+		t.Setenv("TERM", "screen-256color")
+		t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+
+		var buffer bytes.Buffer
+
+		err := clipboard.WriteOSC52(&buffer, "hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(buffer.String(), "\x1bPtmux;") {
+			t.Fatalf("expected tmux passthrough, got %q", buffer.String())
+		}
+	})
+}
@@ -0,0 +1,294 @@
+package completion
+
+import (
+	"strings"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+type Kind string
+
+const (
+	KindColumn   Kind = "column"
+	KindTable    Kind = "table"
+	KindSchema   Kind = "schema"
+	KindFunction Kind = "function"
+	KindKeyword  Kind = "keyword"
+)
+
+// Candidate is a completion for the word before the cursor. Insert
+// replaces the word; Label is what the menu shows.
+type Candidate struct {
+	Label  string
+	Insert string
+	Kind   Kind
+}
+
+type tableRef struct {
+	schema string
+	name   string
+	alias  string
+}
+
+//nolint:gochecknoglobals
+var keywords = []string{
+	"ALTER", "AND", "AS", "ASC", "BEGIN", "BETWEEN", "BY", "CASE", "COMMIT",
+	"CREATE", "CROSS", "DEFAULT", "DELETE", "DESC", "DISTINCT", "DROP",
+	"ELSE", "END", "EXCEPT", "EXISTS", "EXPLAIN", "FALSE", "FROM", "FULL",
+	"GROUP", "HAVING", "ILIKE", "IN", "INDEX", "INNER", "INSERT", "INTERSECT",
+	"INTO", "IS", "JOIN", "LEFT", "LIKE", "LIMIT", "NOT", "NULL", "OFFSET",
+	"ON", "OR", "ORDER", "OUTER", "RETURNING", "RIGHT", "ROLLBACK", "SELECT",
+	"SET", "TABLE", "THEN", "TRUE", "TRUNCATE", "UNION", "UPDATE", "USING",
+	"VALUES", "VIEW", "WHEN", "WHERE", "WITH",
+}
+
+// Complete returns the word being typed at byte offset cursor in text and
+// the candidates that could replace it. Columns are offered for tables the
+// surrounding statement reads from, or for the table or alias the word is
+// qualified with.
+func Complete(catalog db.Catalog, text string, cursor int) (string, []Candidate) {
+	before := text[:cursor]
+	word := before[len(strings.TrimRightFunc(before, isIdentifierRune)):]
+	refs := tableRefs(db.StatementAt(text, cursor))
+
+	var candidates []Candidate
+
+	if qualifier, ok := qualifierBefore(before[:len(before)-len(word)]); ok {
+		candidates = qualifiedCandidates(catalog, refs, qualifier)
+	} else {
+		candidates = unqualifiedCandidates(catalog, refs, word)
+	}
+
+	return word, filter(candidates, word)
+}
+
+func qualifiedCandidates(catalog db.Catalog, refs []tableRef, qualifier string) []Candidate {
+	var candidates []Candidate
+
+	for _, ref := range refs {
+		if strings.EqualFold(ref.alias, qualifier) || (ref.alias == "" && strings.EqualFold(ref.name, qualifier)) {
+			if table, ok := findTable(catalog, ref); ok {
+				candidates = append(candidates, columnCandidates(table)...)
+			}
+		}
+	}
+
+	for _, schema := range catalog.Schemas {
+		if !strings.EqualFold(schema.Name, qualifier) {
+			continue
+		}
+
+		for _, table := range schema.Tables {
+			candidates = append(candidates, Candidate{
+				Label:  table.Name,
+				Insert: db.QuoteIdentifier(table.Name),
+				Kind:   KindTable,
+			})
+		}
+	}
+
+	return candidates
+}
+
+func unqualifiedCandidates(catalog db.Catalog, refs []tableRef, word string) []Candidate {
+	var candidates []Candidate
+
+	for _, ref := range refs {
+		if table, ok := findTable(catalog, ref); ok {
+			candidates = append(candidates, columnCandidates(table)...)
+		}
+	}
+
+	for _, schema := range catalog.Schemas {
+		for _, table := range schema.Tables {
+			candidates = append(candidates, Candidate{
+				Label:  table.Name,
+				Insert: table.QualifiedName(),
+				Kind:   KindTable,
+			})
+		}
+
+		candidates = append(candidates, Candidate{
+			Label:  schema.Name,
+			Insert: db.QuoteIdentifier(schema.Name) + ".",
+			Kind:   KindSchema,
+		})
+	}
+
+	for _, function := range catalog.Functions {
+		candidates = append(candidates, Candidate{
+			Label:  function,
+			Insert: db.QuoteIdentifier(function) + "(",
+			Kind:   KindFunction,
+		})
+	}
+
+	lower := word != "" && word == strings.ToLower(word)
+
+	for _, keyword := range keywords {
+		insert := keyword
+		if lower {
+			insert = strings.ToLower(keyword)
+		}
+
+		candidates = append(candidates, Candidate{Label: keyword, Insert: insert, Kind: KindKeyword})
+	}
+
+	return candidates
+}
+
+func columnCandidates(table db.Table) []Candidate {
+	candidates := make([]Candidate, 0, len(table.Columns))
+
+	for _, column := range table.Columns {
+		candidates = append(candidates, Candidate{
+			Label:  column.Name,
+			Insert: db.QuoteIdentifier(column.Name),
+			Kind:   KindColumn,
+		})
+	}
+
+	return candidates
+}
+
+// filter keeps the candidates starting with word, ignoring case, dropping
+// repeats of an insertion already offered.
+func filter(candidates []Candidate, word string) []Candidate {
+	var filtered []Candidate
+
+	seen := map[string]bool{}
+	word = strings.ToLower(word)
+
+	for _, candidate := range candidates {
+		if !strings.HasPrefix(strings.ToLower(candidate.Label), word) || seen[candidate.Insert] {
+			continue
+		}
+
+		seen[candidate.Insert] = true
+		filtered = append(filtered, candidate)
+	}
+
+	return filtered
+}
+
+// qualifierBefore returns the identifier before a trailing dot, e.g. "u"
+// for "select u.".
+func qualifierBefore(before string) (string, bool) {
+	before, ok := strings.CutSuffix(before, ".")
+	if !ok {
+		return "", false
+	}
+
+	if strings.HasSuffix(before, `"`) {
+		start := strings.LastIndex(before[:len(before)-1], `"`)
+		if start < 0 {
+			return "", false
+		}
+
+		return before[start+1 : len(before)-1], true
+	}
+
+	qualifier := before[len(strings.TrimRightFunc(before, isIdentifierRune)):]
+
+	return qualifier, qualifier != ""
+}
+
+// tableRefs lists the tables named after FROM, JOIN, UPDATE and INTO,
+// including comma separated FROM lists and aliases.
+func tableRefs(statement string) []tableRef {
+	words := db.Words(statement)
+
+	var refs []tableRef
+
+	for i, word := range words {
+		switch {
+		case word.Quoted:
+			continue
+		case word.Text == "from", word.Text == "join", word.Text == "update", word.Text == "into":
+		default:
+			continue
+		}
+
+		for next := i + 1; ; next++ {
+			ref, end, ok := parseTableRef(words, next)
+			if !ok {
+				break
+			}
+
+			refs = append(refs, ref)
+			next = end
+
+			if next >= len(words) || !isUnquoted(words[next], ",") {
+				break
+			}
+		}
+	}
+
+	return refs
+}
+
+func parseTableRef(words []db.Word, i int) (tableRef, int, bool) {
+	ref := tableRef{schema: "", name: "", alias: ""}
+
+	if i >= len(words) || !isIdentifier(words[i]) {
+		return ref, i, false
+	}
+
+	ref.name = words[i].Text
+	i++
+
+	if i+1 < len(words) && isUnquoted(words[i], ".") && isIdentifier(words[i+1]) {
+		ref.schema = ref.name
+		ref.name = words[i+1].Text
+		i += 2
+	}
+
+	if i < len(words) && isUnquoted(words[i], "as") {
+		i++
+	}
+
+	if i < len(words) && isIdentifier(words[i]) && (words[i].Quoted || !isKeyword(words[i].Text)) {
+		ref.alias = words[i].Text
+		i++
+	}
+
+	return ref, i, true
+}
+
+func findTable(catalog db.Catalog, ref tableRef) (db.Table, bool) {
+	for _, schema := range catalog.Schemas {
+		if ref.schema != "" && !strings.EqualFold(schema.Name, ref.schema) {
+			continue
+		}
+
+		for _, table := range schema.Tables {
+			if strings.EqualFold(table.Name, ref.name) {
+				return table, true
+			}
+		}
+	}
+
+	return db.Table{}, false
+}
+
+func isIdentifier(word db.Word) bool {
+	return word.Quoted || strings.TrimLeftFunc(word.Text, isIdentifierRune) == ""
+}
+
+func isUnquoted(word db.Word, text string) bool {
+	return !word.Quoted && word.Text == text
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || r == '$' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127
+}
+
+func isKeyword(token string) bool {
+	for _, keyword := range keywords {
+		if strings.EqualFold(keyword, token) {
+			return true
+		}
+	}
+
+	return false
+}
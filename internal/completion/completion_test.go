@@ -0,0 +1,163 @@
+package completion_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/completion"
+	"github.com/jshawl/dbq/internal/db"
+)
+
+func makeCatalog() db.Catalog {
+	return db.Catalog{
+		Schemas: []db.Schema{
+			{Name: "public", Tables: []db.Table{
+				makeTable("public", "posts", "id", "user_id", "title"),
+				makeTable("public", "users", "id", "email", "Name"),
+			}},
+			{Name: "audit", Tables: []db.Table{
+				makeTable("audit", "events", "id", "payload"),
+			}},
+		},
+		Functions: []string{"count", "now", "upper"},
+	}
+}
+
+func makeTable(schema string, name string, columns ...string) db.Table {
+	table := db.Table{
+		Schema:      schema,
+		Name:        name,
+		Kind:        db.TableKindTable,
+		Columns:     nil,
+		Indexes:     nil,
+		ForeignKeys: nil,
+		PrimaryKey:  nil,
+	}
+
+	for _, column := range columns {
+		table.Columns = append(table.Columns, db.TableColumn{Name: column, Type: "text", Nullable: true})
+	}
+
+	return table
+}
+
+// complete completes at the "|" in text and returns the insertions offered.
+func complete(t *testing.T, text string) (string, []string) {
+	t.Helper()
+
+	cursor := strings.Index(text, "|")
+	word, candidates := completion.Complete(makeCatalog(), strings.Replace(text, "|", "", 1), cursor)
+
+	inserts := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		inserts[i] = candidate.Insert
+	}
+
+	return word, inserts
+}
+
+func TestComplete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		word string
+		want []string
+	}{
+		{
+			name: "keywords keep the case typed",
+			text: "sel|",
+			word: "sel",
+			want: []string{"select"},
+		},
+		{
+			name: "keywords",
+			text: "SEL|",
+			word: "SEL",
+			want: []string{"SELECT"},
+		},
+		{
+			name: "tables",
+			text: "select * from u|",
+			word: "u",
+			want: []string{"users", "upper(", "union", "update", "using"},
+		},
+		{
+			name: "tables outside the default schema are qualified",
+			text: "select * from ev|",
+			word: "ev",
+			want: []string{"audit.events"},
+		},
+		{
+			name: "columns of tables in the from clause",
+			text: "select ti| from posts",
+			word: "ti",
+			want: []string{"title"},
+		},
+		{
+			name: "columns are scoped to the from clause",
+			text: "select em| from posts",
+			word: "em",
+			want: nil,
+		},
+		{
+			name: "columns of joined tables",
+			text: "select e| from posts p join users u on u.id = p.user_id",
+			word: "e",
+			want: []string{"email", "audit.events", "else", "end", "except", "exists", "explain"},
+		},
+		{
+			name: "columns by alias",
+			text: "select u.| from posts p, users as u",
+			word: "",
+			want: []string{"id", "email", `"Name"`},
+		},
+		{
+			name: "columns by table name",
+			text: "select posts.t| from posts",
+			word: "t",
+			want: []string{"title"},
+		},
+		{
+			name: "tables by schema",
+			text: "select * from audit.|",
+			word: "",
+			want: []string{"events"},
+		},
+		{
+			name: "functions",
+			text: "select cou| from posts",
+			word: "cou",
+			want: []string{"count("},
+		},
+		{
+			name: "statement under the cursor",
+			text: "select * from users; select ti| from posts; select 1",
+			word: "ti",
+			want: []string{"title"},
+		},
+		{
+			name: "semicolons in strings and comments do not end the statement",
+			text: "select ti|, ';' -- ;\nfrom posts",
+			word: "ti",
+			want: []string{"title"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			word, got := complete(t, test.text)
+			if word != test.word {
+				t.Fatalf("expected word %q, got %q", test.word, word)
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
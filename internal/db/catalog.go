@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Catalog describes the objects in a database, as listed by the schema
+// browser and offered by completion.
+type Catalog struct {
+	Schemas   []Schema
+	Functions []string
+}
+
+type Schema struct {
+	Name   string
+	Tables []Table
+}
+
+type TableKind string
+
+const (
+	TableKindTable            TableKind = "table"
+	TableKindView             TableKind = "view"
+	TableKindMaterializedView TableKind = "materialized view"
+	TableKindForeignTable     TableKind = "foreign table"
+)
+
+type Table struct {
+	Schema string
+	Name   string
+	// OID identifies the table to Postgres, and is zero on SQLite.
+	OID         uint32
+	Kind        TableKind
+	Columns     []TableColumn
+	Indexes     []Index
+	ForeignKeys []ForeignKey
+	// PrimaryKey names the primary key's columns in key order, and is
+	// empty for tables without one and for views.
+	PrimaryKey []string
+}
+
+// QualifiedName returns the table's name quoted for use in a query, with
+// its schema unless that is the default one.
+func (table Table) QualifiedName() string {
+	name := QuoteIdentifier(table.Name)
+	if table.Schema == "public" || table.Schema == "main" {
+		return name
+	}
+
+	return QuoteIdentifier(table.Schema) + "." + name
+}
+
+type TableColumn struct {
+	Name     string
+	Type     string
+	Nullable bool
+	// AttributeNumber is the column's attnum in Postgres, and is zero on
+	// SQLite.
+	AttributeNumber uint16
+}
+
+type Index struct {
+	Name       string
+	Definition string
+}
+
+type ForeignKey struct {
+	Name       string
+	Definition string
+}
+
+// Introspector is implemented by backends that can describe their schema.
+type Introspector interface {
+	Catalog(ctx context.Context) (Catalog, error)
+}
+
+var (
+	ErrCatalog            = errors.New("failed to load catalog")
+	ErrCatalogUnsupported = errors.New("backend does not support introspection")
+)
+
+func (db *DB) Catalog(ctx context.Context) (Catalog, error) {
+	introspector, ok := db.inner.(Introspector)
+	if !ok {
+		return Catalog{}, ErrCatalogUnsupported
+	}
+
+	catalog, err := introspector.Catalog(ctx)
+	if err != nil {
+		return Catalog{}, fmt.Errorf("%w: %w", ErrCatalog, err)
+	}
+
+	return catalog, nil
+}
+
+// catalogBuilder assembles tables fetched schema by schema and table by
+// table into a Catalog, keeping the order they were added in.
+type catalogBuilder struct {
+	catalog Catalog
+	schemas map[string]int
+	tables  map[[2]string]*Table
+}
+
+func newCatalogBuilder() *catalogBuilder {
+	return &catalogBuilder{
+		catalog: Catalog{Schemas: nil, Functions: nil},
+		schemas: map[string]int{},
+		tables:  map[[2]string]*Table{},
+	}
+}
+
+func (builder *catalogBuilder) addSchema(name string) {
+	if _, ok := builder.schemas[name]; ok {
+		return
+	}
+
+	builder.schemas[name] = len(builder.catalog.Schemas)
+	builder.catalog.Schemas = append(builder.catalog.Schemas, Schema{Name: name, Tables: nil})
+}
+
+func (builder *catalogBuilder) addTable(schema string, name string, kind TableKind, oid uint32) {
+	builder.addSchema(schema)
+
+	index := builder.schemas[schema]
+	builder.catalog.Schemas[index].Tables = append(builder.catalog.Schemas[index].Tables, Table{
+		Schema:      schema,
+		Name:        name,
+		OID:         oid,
+		Kind:        kind,
+		Columns:     nil,
+		Indexes:     nil,
+		ForeignKeys: nil,
+		PrimaryKey:  nil,
+	})
+}
+
+// table returns the table added under schema and name, or nil. Pointers
+// are resolved after all tables are added so appends cannot move them.
+func (builder *catalogBuilder) table(schema string, name string) *Table {
+	if len(builder.tables) == 0 {
+		for i := range builder.catalog.Schemas {
+			for j := range builder.catalog.Schemas[i].Tables {
+				table := &builder.catalog.Schemas[i].Tables[j]
+				builder.tables[[2]string{table.Schema, table.Name}] = table
+			}
+		}
+	}
+
+	return builder.tables[[2]string{schema, name}]
+}
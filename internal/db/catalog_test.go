@@ -0,0 +1,143 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+func findTable(t *testing.T, catalog db.Catalog, schema string, name string) db.Table {
+	t.Helper()
+
+	for _, s := range catalog.Schemas {
+		for _, table := range s.Tables {
+			if s.Name == schema && table.Name == name {
+				return table
+			}
+		}
+	}
+
+	t.Fatalf("expected catalog to contain %s.%s, got %+v", schema, name, catalog)
+
+	return db.Table{}
+}
+
+func TestDB_Catalog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sqlite", func(t *testing.T) {
+		t.Parallel()
+
+		sqlitedb := setupSQLiteDatabase(t)
+
+		for _, statement := range []string{
+			"create table posts (id integer primary key, user_id integer not null references users(id), title text)",
+			"create index idx_posts_user_id on posts(user_id)",
+			"create view titles as select title from posts",
+			"create table comments (id integer primary key, post_id integer references posts)",
+		} {
+			_, err := sqlitedb.Query(context.Background(), statement)
+			if err != nil {
+				t.Fatalf("setup failed: %v", err)
+			}
+		}
+
+		catalog, err := db.NewDB(sqlitedb).Catalog(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		posts := findTable(t, catalog, "main", "posts")
+		if posts.Kind != db.TableKindTable {
+			t.Fatalf("expected posts to be a table, got %s", posts.Kind)
+		}
+
+		wantColumn := db.TableColumn{Name: "user_id", Type: "integer", Nullable: false}
+		if len(posts.Columns) != 3 || posts.Columns[1] != wantColumn {
+			t.Fatalf("expected posts columns in order, got %+v", posts.Columns)
+		}
+
+		if !slices.Equal(posts.PrimaryKey, []string{"id"}) {
+			t.Fatalf("expected posts primary key, got %v", posts.PrimaryKey)
+		}
+
+		if len(posts.Indexes) != 1 || posts.Indexes[0].Name != "idx_posts_user_id" {
+			t.Fatalf("expected posts index, got %+v", posts.Indexes)
+		}
+
+		if len(posts.ForeignKeys) != 1 ||
+			posts.ForeignKeys[0].Definition != "FOREIGN KEY (user_id) REFERENCES users(id)" {
+			t.Fatalf("expected posts foreign key, got %+v", posts.ForeignKeys)
+		}
+
+		comments := findTable(t, catalog, "main", "comments")
+		if len(comments.ForeignKeys) != 1 ||
+			comments.ForeignKeys[0].Definition != "FOREIGN KEY (post_id) REFERENCES posts(id)" {
+			t.Fatalf("expected the parent's primary key referenced, got %+v", comments.ForeignKeys)
+		}
+
+		titles := findTable(t, catalog, "main", "titles")
+		if titles.Kind != db.TableKindView {
+			t.Fatalf("expected titles to be a view, got %s", titles.Kind)
+		}
+
+		if !slices.Contains(catalog.Functions, "coalesce") {
+			t.Fatalf("expected builtin functions, got %v", catalog.Functions)
+		}
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		t.Parallel()
+
+		catalog, err := db.NewDB(setupDatabase(t, DSN)).Catalog(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		posts := findTable(t, catalog, "public", "posts")
+		if len(posts.ForeignKeys) != 1 {
+			t.Fatalf("expected posts foreign key, got %+v", posts.ForeignKeys)
+		}
+
+		if !slices.Equal(posts.PrimaryKey, []string{"id"}) {
+			t.Fatalf("expected posts primary key, got %v", posts.PrimaryKey)
+		}
+
+		if posts.OID == 0 || posts.Columns[1].Name != "user_id" || posts.Columns[1].AttributeNumber != 2 {
+			t.Fatalf("expected posts oid and attribute numbers, got %d, %+v", posts.OID, posts.Columns)
+		}
+
+		if len(posts.Indexes) != 3 {
+			t.Fatalf("expected posts indexes, got %+v", posts.Indexes)
+		}
+
+		view := findTable(t, catalog, "public", "posts_with_users")
+		if view.Kind != db.TableKindView {
+			t.Fatalf("expected posts_with_users to be a view, got %s", view.Kind)
+		}
+
+		if !slices.Contains(catalog.Functions, "now") {
+			t.Fatalf("expected builtin functions, got %d", len(catalog.Functions))
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+
+		mock := &mockPGDB{
+			closeCalled: false,
+			closeErr:    nil,
+			results:     db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
+			queryCalled: false,
+			queryErr:    nil,
+		}
+
+		_, err := db.NewDB(mock).Catalog(context.Background())
+		if !errors.Is(err, db.ErrCatalogUnsupported) {
+			t.Fatalf("expected ErrCatalogUnsupported, got %v", err)
+		}
+	})
+}
@@ -7,10 +7,32 @@ import (
 	"time"
 )
 
-type QueryResult []map[string]interface{}
+// Column describes a result column in SELECT order. TypeOID is the
+// Postgres type oid and is 0 for backends without one.
+type Column struct {
+	Name     string
+	TypeOID  uint32
+	TypeName string
+	// TableOID and TableAttributeNumber name the table column a result
+	// column was read straight from. Postgres leaves them zero for
+	// expressions, and SQLite does not report them.
+	TableOID             uint32
+	TableAttributeNumber uint16
+}
+
+// QueryResult holds rows as value slices indexed like Columns, so column
+// order and duplicate column names survive. CommandTag is the server's
+// completion tag, e.g. "UPDATE 5" or "CREATE TABLE".
+type QueryResult struct {
+	Columns    []Column
+	Rows       [][]interface{}
+	CommandTag string
+}
 
+// Queryable is a backend. Query binds args to the $1, $2 and so on in
+// sql.
 type Queryable interface {
-	Query(ctx context.Context, sql string) (QueryResult, error)
+	Query(ctx context.Context, sql string, args ...any) (QueryResult, error)
 	Close(ctx context.Context) error
 }
 
@@ -22,27 +44,87 @@ type DBQueryResult struct {
 	Err      error
 	Results  QueryResult
 	Duration time.Duration
+	Query    string
+	// Truncated reports that Results stopped at a row cap with more rows
+	// left unread.
+	Truncated bool
 }
 
 var (
-	ErrDBQuery = errors.New("failed to call Query")
-	ErrDBClose = errors.New("failed to call Close")
+	ErrDBQuery  = errors.New("failed to call Query")
+	ErrDBClose  = errors.New("failed to call Close")
+	ErrCanceled = errors.New("query canceled")
 )
 
 func NewDB(inner Queryable) *DB {
 	return &DB{inner: inner}
 }
 
-func (db *DB) Query(ctx context.Context, query string) DBQueryResult {
+// Open connects to the backend selected by the dsn: sqlite:// and file:
+// urls open SQLite databases, anything else is handed to Postgres.
+// Options adjust the session Open starts.
+type Options struct {
+	// Schema is searched before the default schema for unqualified names.
+	Schema string
+	// ReadOnly makes the database reject writes for the whole session.
+	ReadOnly bool
+}
+
+func Open(ctx context.Context, dsn string, options Options) (Queryable, error) {
+	if IsSQLiteDSN(dsn) {
+		sqlitedb, err := NewSQLiteDB(ctx, dsn, options)
+		if err != nil {
+			return nil, err
+		}
+
+		return sqlitedb, nil
+	}
+
+	pgdb, err := NewPostgresDB(ctx, dsn, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgdb, nil
+}
+
+func (db *DB) Query(ctx context.Context, query string, args ...any) DBQueryResult {
 	start := time.Now()
 
-	postgresQueryResults, err := db.inner.Query(ctx, query)
+	postgresQueryResults, err := db.inner.Query(ctx, query, args...)
+	if err != nil {
+		err = canceled(ctx, err)
+	}
 
 	return DBQueryResult{
-		Err:      err,
-		Results:  postgresQueryResults,
-		Duration: time.Since(start),
+		Err:       err,
+		Results:   postgresQueryResults,
+		Duration:  time.Since(start),
+		Query:     query,
+		Truncated: false,
+	}
+}
+
+// QueryStatements runs each statement in sql in order on the same
+// connection, stopping at the first error.
+func (db *DB) QueryStatements(ctx context.Context, sql string) []DBQueryResult {
+	statements := SplitStatements(sql)
+	if len(statements) <= 1 {
+		return []DBQueryResult{db.Query(ctx, sql)}
 	}
+
+	results := make([]DBQueryResult, 0, len(statements))
+
+	for _, statement := range statements {
+		result := db.Query(ctx, statement)
+		results = append(results, result)
+
+		if result.Err != nil {
+			break
+		}
+	}
+
+	return results
 }
 
 func (db *DB) Close(ctx context.Context) error {
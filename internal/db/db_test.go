@@ -17,7 +17,7 @@ type mockPGDB struct {
 	closeErr    error
 }
 
-func (m *mockPGDB) Query(_ context.Context, _ string) (db.QueryResult, error) {
+func (m *mockPGDB) Query(_ context.Context, _ string, _ ...any) (db.QueryResult, error) {
 	m.queryCalled = true
 
 	return m.results, m.queryErr
@@ -31,6 +31,41 @@ func (m *mockPGDB) Close(_ context.Context) error {
 
 var ErrTestQuery = errors.New("boom")
 
+func assertQueryResult(t *testing.T, want db.QueryResult, have db.QueryResult) {
+	t.Helper()
+
+	if len(have.Columns) != len(want.Columns) {
+		t.Fatalf("want %d columns, got %d", len(want.Columns), len(have.Columns))
+	}
+
+	for i, column := range want.Columns {
+		// table oids vary from one test database to the next, so where a
+		// column was read from is checked on its own
+		haveColumn := have.Columns[i]
+		haveColumn.TableOID, haveColumn.TableAttributeNumber = 0, 0
+
+		if haveColumn != column {
+			t.Errorf("column %d: want %+v, got %+v", i, column, have.Columns[i])
+		}
+	}
+
+	if have.CommandTag != want.CommandTag {
+		t.Errorf("want command tag %q, got %q", want.CommandTag, have.CommandTag)
+	}
+
+	if len(have.Rows) != len(want.Rows) {
+		t.Fatalf("want %d rows, got %d", len(want.Rows), len(have.Rows))
+	}
+
+	for i := range want.Rows {
+		for j, v := range want.Rows[i] {
+			if fmt.Sprintf("%v", have.Rows[i][j]) != fmt.Sprintf("%v", v) {
+				t.Errorf("row %d column %d: want %v, got %v", i, j, v, have.Rows[i][j])
+			}
+		}
+	}
+}
+
 func TestDB_Query(t *testing.T) {
 	t.Parallel()
 
@@ -38,8 +73,15 @@ func TestDB_Query(t *testing.T) {
 		t.Parallel()
 
 		want := db.QueryResult{
-			{"id": int32(1), "name": "Alice"},
-			{"id": int32(2), "name": "Bob"},
+			Columns: []db.Column{
+				{Name: "id", TypeOID: 23, TypeName: "int4"},
+				{Name: "name", TypeOID: 25, TypeName: "text"},
+			},
+			Rows: [][]interface{}{
+				{int32(1), "Alice"},
+				{int32(2), "Bob"},
+			},
+			CommandTag: "SELECT 2",
 		}
 		mock := &mockPGDB{
 			closeCalled: false,
@@ -58,13 +100,7 @@ func TestDB_Query(t *testing.T) {
 			t.Error("expected Query to call inner PGDB.Query")
 		}
 
-		for i := range want {
-			for k, v := range want[i] {
-				if got.Results[i][k] != v {
-					t.Errorf("row %d column %s: want %v, got %v", i, k, v, got.Results[i][k])
-				}
-			}
-		}
+		assertQueryResult(t, want, got.Results)
 
 		if got.Duration <= 0 {
 			t.Error("expected positive Duration")
@@ -77,16 +113,41 @@ func TestDB_Query(t *testing.T) {
 		mock := &mockPGDB{
 			closeCalled: false,
 			closeErr:    nil,
-			results:     nil,
+			results:     db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
 			queryCalled: false,
 			queryErr:    fmt.Errorf("%w", ErrTestQuery),
 		}
-		db := db.NewDB(mock)
+		database := db.NewDB(mock)
 
-		got := db.Query(context.Background(), "bad sql")
+		got := database.Query(context.Background(), "bad sql")
 		if got.Err == nil {
 			t.Fatal("expected an error, got nil")
 		}
+
+		if errors.Is(got.Err, db.ErrCanceled) {
+			t.Fatal("expected error not to be a cancellation")
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		mock := &mockPGDB{
+			closeCalled: false,
+			closeErr:    nil,
+			results:     db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
+			queryCalled: false,
+			queryErr:    fmt.Errorf("%w", context.Canceled),
+		}
+		database := db.NewDB(mock)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		got := database.Query(ctx, "select pg_sleep(10)")
+		if !errors.Is(got.Err, db.ErrCanceled) {
+			t.Fatalf("expected ErrCanceled, got %v", got.Err)
+		}
 	})
 }
 
@@ -96,7 +157,7 @@ func TestDB_Close(t *testing.T) {
 	mock := &mockPGDB{
 		closeCalled: false,
 		closeErr:    nil,
-		results:     nil,
+		results:     db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
 		queryCalled: false,
 		queryErr:    fmt.Errorf("%w", ErrTestQuery),
 	}
@@ -111,3 +172,58 @@ func TestDB_Close(t *testing.T) {
 		t.Error("expected Close to call inner PGDB.Close")
 	}
 }
+
+func TestDB_QueryStatements(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs statements in order", func(t *testing.T) {
+		t.Parallel()
+
+		database := db.NewDB(setupSQLiteDatabase(t))
+
+		got := database.QueryStatements(
+			context.Background(),
+			"update users set first_name = 'Jim' where id = 1; select first_name from users where id = 1;",
+		)
+
+		if len(got) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(got))
+		}
+
+		if got[0].Query != "update users set first_name = 'Jim' where id = 1" {
+			t.Fatalf("expected statement to be recorded, got %s", got[0].Query)
+		}
+
+		if fmt.Sprintf("%v", got[1].Results.Rows) != "[[Jim]]" {
+			t.Fatalf("expected update to be visible, got %v", got[1].Results.Rows)
+		}
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		t.Parallel()
+
+		database := db.NewDB(setupSQLiteDatabase(t))
+
+		got := database.QueryStatements(context.Background(), "select 1; not sql; select 2")
+
+		if len(got) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(got))
+		}
+
+		if got[1].Err == nil {
+			t.Fatal("expected second statement to fail")
+		}
+	})
+
+	t.Run("single statement is sent verbatim", func(t *testing.T) {
+		t.Parallel()
+
+		database := db.NewDB(setupSQLiteDatabase(t))
+
+		got := database.QueryStatements(context.Background(), "select 1;")
+
+		if len(got) != 1 || got[0].Query != "select 1;" {
+			t.Fatalf("expected one verbatim statement, got %+v", got)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package db
+
+// alterDropsSetting lists what an ALTER may drop without losing data.
+//
+//nolint:gochecknoglobals
+var alterDropsSetting = map[string]bool{
+	"default": true, "not": true, "expression": true, "identity": true,
+}
+
+// Destructive returns the statements in sql that drop or truncate, that
+// alter a table to drop a column or constraint, or that delete or update
+// every row for want of a WHERE.
+func Destructive(sql string) []string {
+	var statements []string
+
+	for _, statement := range SplitStatements(sql) {
+		if isDestructive(Words(statement)) {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements
+}
+
+func isDestructive(words []Word) bool {
+	verb, rest := statementVerb(words)
+
+	switch verb {
+	case "drop", "truncate":
+		return true
+	case "alter":
+		return alterDrops(rest)
+	case "delete", "update":
+		return !hasTopLevel(rest, "where")
+	}
+
+	return false
+}
+
+// alterDrops reports whether an ALTER drops something other than a
+// column's default, NOT NULL, generation expression or identity.
+func alterDrops(words []Word) bool {
+	depth := 0
+
+	for i, word := range words {
+		switch {
+		case word.Quoted:
+		case word.Text == "(":
+			depth++
+		case word.Text == ")":
+			depth--
+		case depth == 0 && word.Text == "drop":
+			next := i + 1
+			if next == len(words) || words[next].Quoted || !alterDropsSetting[words[next].Text] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasTopLevel reports whether keyword appears outside parentheses.
+func hasTopLevel(words []Word, keyword string) bool {
+	depth := 0
+
+	for _, word := range words {
+		switch {
+		case word.Quoted:
+		case word.Text == "(":
+			depth++
+		case word.Text == ")":
+			depth--
+		case depth == 0 && word.Text == keyword:
+			return true
+		}
+	}
+
+	return false
+}
+
+// readOnlySettings are the parameters a read-only session rests on.
+//
+//nolint:gochecknoglobals
+var readOnlySettings = map[string]bool{
+	"default_transaction_read_only": true, "transaction_read_only": true,
+}
+
+//nolint:gochecknoglobals
+var readOnlyValues = map[string]bool{"on": true, "true": true, "yes": true, "default": true}
+
+// LiftsReadOnly returns the statements in sql that would let a read-only
+// session write: those starting or setting a transaction READ WRITE, and
+// those setting default_transaction_read_only to anything but on.
+func LiftsReadOnly(sql string) []string {
+	var statements []string
+
+	for _, statement := range SplitStatements(sql) {
+		if liftsReadOnly(Words(statement)) {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements
+}
+
+func liftsReadOnly(words []Word) bool {
+	verb, rest := statementVerb(words)
+
+	switch verb {
+	case "begin", "start":
+		return hasReadWrite(rest)
+	case "set":
+		return hasReadWrite(rest) || setsReadOnlyOff(rest)
+	}
+
+	return false
+}
+
+func hasReadWrite(words []Word) bool {
+	for i := 1; i < len(words); i++ {
+		if isKeyword(words[i-1], "read") && isKeyword(words[i], "write") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setsReadOnlyOff reports whether a SET gives a read-only setting a value
+// other than on, counting values in strings, which words do not keep.
+func setsReadOnlyOff(words []Word) bool {
+	for i, word := range words {
+		if !readOnlySettings[word.Text] {
+			continue
+		}
+
+		value := i + 1
+		if value < len(words) && (isKeyword(words[value], "to") || isKeyword(words[value], "=")) {
+			value++
+		}
+
+		return value < len(words) && (words[value].Quoted || !readOnlyValues[words[value].Text])
+	}
+
+	return false
+}
+
+func isKeyword(word Word, keyword string) bool {
+	return !word.Quoted && word.Text == keyword
+}
@@ -0,0 +1,83 @@
+package db_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+func TestDestructive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{"select", "select * from users", nil},
+		{"drop", "DROP TABLE users", []string{"DROP TABLE users"}},
+		{"truncate", "truncate users", []string{"truncate users"}},
+		{"delete with where", "delete from users where id = 1", nil},
+		{"delete without where", "delete from users", []string{"delete from users"}},
+		{"update without where", "UPDATE users SET email = NULL", []string{"UPDATE users SET email = NULL"}},
+		{"where in a subquery", "update a set n = (select 1 where true)", []string{"update a set n = (select 1 where true)"}},
+		{"where in a string", "delete from a returning 'where'", []string{"delete from a returning 'where'"}},
+		{"quoted where", `delete from "where"`, []string{`delete from "where"`}},
+		{"with", "with a as (select 1 where true) delete from b", []string{"with a as (select 1 where true) delete from b"}},
+		{"with and where", "with a as (select 1) delete from b where id in (select * from a)", nil},
+		{"alter drop column", "alter table users drop column email", []string{"alter table users drop column email"}},
+		{"alter drop", `ALTER TABLE users ADD x int, DROP "email"`, []string{`ALTER TABLE users ADD x int, DROP "email"`}},
+		{"alter drop constraint", "alter table users drop constraint users_pkey", []string{"alter table users drop constraint users_pkey"}},
+		{"alter drop default", "alter table users alter column email drop default, alter id drop not null", nil},
+		{"comment", "-- drop table users\nselect 1", nil},
+		{"several", "begin; drop table a; select 1; truncate b", []string{"drop table a", "truncate b"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := db.Destructive(test.sql)
+			if !slices.Equal(got, test.want) {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestLiftsReadOnly(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{"select", "select 1", nil},
+		{"set off", "SET default_transaction_read_only = off", []string{"SET default_transaction_read_only = off"}},
+		{"set session to false", "set session default_transaction_read_only to false", []string{"set session default_transaction_read_only to false"}},
+		{"set a string", "set default_transaction_read_only = 'on'", []string{"set default_transaction_read_only = 'on'"}},
+		{"set on", "set default_transaction_read_only = on", nil},
+		{"set transaction_read_only", "set transaction_read_only = off", []string{"set transaction_read_only = off"}},
+		{"begin read write", "BEGIN READ WRITE", []string{"BEGIN READ WRITE"}},
+		{"start transaction", "start transaction isolation level serializable, read write", []string{"start transaction isolation level serializable, read write"}},
+		{"begin read only", "begin read only", nil},
+		{"set transaction", "set transaction read write", []string{"set transaction read write"}},
+		{"session characteristics", "set session characteristics as transaction read write", []string{"set session characteristics as transaction read write"}},
+		{"other settings", "set search_path = public", nil},
+		{"comment", "-- begin read write\nbegin", nil},
+		{"several", "select 1; begin read write", []string{"begin read write"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := db.LiftsReadOnly(test.sql)
+			if !slices.Equal(got, test.want) {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNotEditable  = errors.New("results are not editable")
+	ErrApplyUpdates = errors.New("failed to apply updates")
+	ErrRowCount     = errors.New("update did not change exactly one row")
+)
+
+// Editable is a result read straight from one table with a primary key,
+// so its cells can be written back with UPDATEs that find each row by
+// its key.
+type Editable struct {
+	Table   Table
+	columns []Column
+	// keys are the indexes of the primary key's columns in the result.
+	keys []int
+}
+
+// NewEditable checks that sql, the statement a result with columns came
+// from, is a plain SELECT from a single table in catalog whose primary
+// key the result holds. Columns are matched to the table by where the
+// server says they were read from, never by name, so an expression
+// aliased as a column is not taken for it. SQLite does not say, so its
+// results are not editable.
+func NewEditable(sql string, columns []Column, catalog Catalog) (Editable, error) {
+	ref, ok := selectedTable(sql)
+	if !ok {
+		return Editable{}, fmt.Errorf("%w: not a select from a single table", ErrNotEditable)
+	}
+
+	table, err := catalog.lookupTable(ref)
+	if err != nil {
+		return Editable{}, err
+	}
+
+	if table.Kind != TableKindTable || len(table.PrimaryKey) == 0 {
+		return Editable{}, fmt.Errorf("%w: %s has no primary key", ErrNotEditable, table.Name)
+	}
+
+	if table.OID == 0 {
+		return Editable{}, fmt.Errorf(
+			"%w: the database does not say which columns of %s were read",
+			ErrNotEditable,
+			table.Name,
+		)
+	}
+
+	editable := Editable{Table: table, columns: columns, keys: nil}
+
+	for _, name := range table.PrimaryKey {
+		index, ok := editable.column(name)
+		if !ok {
+			return Editable{}, fmt.Errorf(
+				"%w: primary key column %s is not selected",
+				ErrNotEditable,
+				name,
+			)
+		}
+
+		editable.keys = append(editable.keys, index)
+	}
+
+	return editable, nil
+}
+
+// CanEdit reports whether the result's column can be written back: it
+// must be read straight from one of the table's own columns, selected
+// once, and not part of the primary key.
+func (editable Editable) CanEdit(column int) error {
+	name := editable.columns[column].Name
+
+	for _, key := range editable.keys {
+		if key == column {
+			return fmt.Errorf("%w: %s is part of the primary key", ErrNotEditable, name)
+		}
+	}
+
+	source, ok := editable.source(column)
+	if !ok {
+		return fmt.Errorf(
+			"%w: %s is not a column of %s",
+			ErrNotEditable,
+			name,
+			editable.Table.Name,
+		)
+	}
+
+	for i := range editable.columns {
+		if other, ok := editable.source(i); ok && i != column && other.Name == source.Name {
+			return fmt.Errorf("%w: %s is selected more than once", ErrNotEditable, name)
+		}
+	}
+
+	return nil
+}
+
+// Update returns the UPDATE setting the result's column to value in the
+// table row that row was read from. A nil value sets NULL.
+func (editable Editable) Update(row []any, column int, value *string) string {
+	set := "NULL"
+	if value != nil {
+		set = QuoteString(*value)
+	}
+
+	conditions := make([]string, len(editable.keys))
+	for i, key := range editable.keys {
+		source, _ := editable.source(key)
+		conditions[i] = QuoteIdentifier(source.Name) + " = " +
+			Literal(row[key], editable.columns[key].TypeName)
+	}
+
+	source, _ := editable.source(column)
+
+	return fmt.Sprintf(
+		"UPDATE %s SET %s = %s WHERE %s",
+		editable.Table.QualifiedName(),
+		QuoteIdentifier(source.Name),
+		set,
+		strings.Join(conditions, " AND "),
+	)
+}
+
+// source returns the table column the result's column was read from.
+func (editable Editable) source(column int) (TableColumn, bool) {
+	result := editable.columns[column]
+	if result.TableOID != editable.Table.OID || result.TableAttributeNumber == 0 {
+		return TableColumn{}, false
+	}
+
+	for _, tableColumn := range editable.Table.Columns {
+		if tableColumn.AttributeNumber == result.TableAttributeNumber {
+			return tableColumn, true
+		}
+	}
+
+	return TableColumn{}, false
+}
+
+// column finds the first result column read from the table column named
+// name, reporting false when there is none.
+func (editable Editable) column(name string) (int, bool) {
+	for i := range editable.columns {
+		if source, ok := editable.source(i); ok && source.Name == name {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// ApplyUpdates runs statements in a transaction, committing only when
+// each changed exactly one row. Inside an open transaction they run in a
+// savepoint instead, left for that transaction to commit.
+func (db *DB) ApplyUpdates(ctx context.Context, statements []string) error {
+	err := db.withSavepoint(ctx, "dbq_edits", true, func() error {
+		for _, statement := range statements {
+			results, err := db.inner.Query(ctx, statement)
+			if err != nil {
+				return err //nolint:wrapcheck // wrapped below
+			}
+
+			if results.CommandTag != "UPDATE 1" {
+				return fmt.Errorf("%w: %s", ErrRowCount, results.CommandTag)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrApplyUpdates, canceled(ctx, err))
+	}
+
+	return nil
+}
+
+// tableRef is a table named in a query, with its schema when qualified.
+// Quoted names are matched exactly and unquoted ones ignoring case.
+type tableRef struct {
+	schema string
+	name   string
+	quoted bool
+}
+
+// editableTail lists what may follow the table in a plain single-table
+// SELECT.
+//
+//nolint:gochecknoglobals
+var editableTail = map[string]bool{
+	"where": true, "order": true, "limit": true, "offset": true, "fetch": true, "for": true,
+}
+
+// notEditable lists what makes a SELECT's rows something other than
+// table rows, wherever it appears outside parentheses.
+//
+//nolint:gochecknoglobals
+var notEditable = map[string]bool{
+	"distinct": true, "group": true, "having": true, "window": true,
+	"union": true, "intersect": true, "except": true,
+}
+
+// selectedTable finds the table a plain SELECT reads from: one table,
+// perhaps aliased, with no joins, grouping or set operations.
+//
+//nolint:cyclop
+func selectedTable(sql string) (tableRef, bool) {
+	words := Words(sql)
+	if len(words) == 0 || words[0].Quoted || words[0].Text != "select" {
+		return tableRef{}, false
+	}
+
+	from := -1
+	depth := 0
+
+	for i, word := range words {
+		switch {
+		case word.Quoted:
+		case word.Text == "(":
+			depth++
+		case word.Text == ")":
+			depth--
+		case depth > 0:
+		case notEditable[word.Text]:
+			return tableRef{}, false
+		case word.Text == "from" && from < 0:
+			from = i
+		}
+	}
+
+	if from < 0 || from+1 >= len(words) {
+		return tableRef{}, false
+	}
+
+	rest := words[from+1:]
+	ref := tableRef{schema: "", name: rest[0].Text, quoted: rest[0].Quoted}
+	rest = rest[1:]
+
+	if len(rest) >= 2 && !rest[0].Quoted && rest[0].Text == "." {
+		ref.schema = ref.name
+		ref.name = rest[1].Text
+		ref.quoted = rest[1].Quoted
+		rest = rest[2:]
+	}
+
+	if !ref.quoted && !isWord(ref.name) {
+		return tableRef{}, false
+	}
+
+	// an alias, with or without AS
+	if len(rest) > 0 && !rest[0].Quoted && rest[0].Text == "as" {
+		rest = rest[1:]
+	}
+
+	if len(rest) > 0 && (rest[0].Quoted || isWord(rest[0].Text) && !editableTail[rest[0].Text]) {
+		rest = rest[1:]
+	}
+
+	if len(rest) > 0 && (rest[0].Quoted || !editableTail[rest[0].Text]) {
+		return tableRef{}, false
+	}
+
+	return ref, true
+}
+
+func isWord(text string) bool {
+	return text != "" && (text[0] == '_' || text[0] >= 'a' && text[0] <= 'z')
+}
+
+// lookupTable finds the table ref names, which must be unambiguous when
+// its schema is left out.
+func (catalog Catalog) lookupTable(ref tableRef) (Table, error) {
+	var found []Table
+
+	matches := func(a string, b string) bool {
+		if ref.quoted {
+			return a == b
+		}
+
+		return strings.EqualFold(a, b)
+	}
+
+	for _, schema := range catalog.Schemas {
+		if ref.schema != "" && !strings.EqualFold(schema.Name, ref.schema) {
+			continue
+		}
+
+		for _, table := range schema.Tables {
+			if matches(table.Name, ref.name) {
+				found = append(found, table)
+			}
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return Table{}, fmt.Errorf("%w: %s is not a known table", ErrNotEditable, ref.name)
+	case 1:
+		return found[0], nil
+	}
+
+	return Table{}, fmt.Errorf("%w: %s is in more than one schema", ErrNotEditable, ref.name)
+}
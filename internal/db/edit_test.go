@@ -0,0 +1,289 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+const (
+	usersOID      = 16384
+	auditUsersOID = 16390
+)
+
+func makeEditCatalog() db.Catalog {
+	users := db.Table{
+		Schema: "public",
+		Name:   "users",
+		OID:    usersOID,
+		Kind:   db.TableKindTable,
+		Columns: []db.TableColumn{
+			{Name: "id", Type: "integer", Nullable: false, AttributeNumber: 1},
+			{Name: "email", Type: "text", Nullable: true, AttributeNumber: 2},
+		},
+		Indexes:     nil,
+		ForeignKeys: nil,
+		PrimaryKey:  []string{"id"},
+	}
+
+	logs := db.Table{
+		Schema:      "audit",
+		Name:        "logs",
+		OID:         16400,
+		Kind:        db.TableKindTable,
+		Columns:     []db.TableColumn{{Name: "message", Type: "text", Nullable: true, AttributeNumber: 1}},
+		Indexes:     nil,
+		ForeignKeys: nil,
+		PrimaryKey:  nil,
+	}
+
+	auditUsers := users
+	auditUsers.Schema = "audit"
+	auditUsers.OID = auditUsersOID
+
+	return db.Catalog{
+		Schemas: []db.Schema{
+			{Name: "public", Tables: []db.Table{users}},
+			{Name: "audit", Tables: []db.Table{logs, auditUsers}},
+		},
+		Functions: nil,
+	}
+}
+
+// userColumns are the columns of a select * from the users table with
+// tableOID.
+func userColumns(tableOID uint32) []db.Column {
+	return []db.Column{
+		{Name: "id", TypeOID: 23, TypeName: "int4", TableOID: tableOID, TableAttributeNumber: 1},
+		{Name: "email", TypeOID: 25, TypeName: "text", TableOID: tableOID, TableAttributeNumber: 2},
+	}
+}
+
+func TestNewEditable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		sql     string
+		columns []db.Column
+		wantErr bool
+	}{
+		{"plain select", "select * from public.users", userColumns(usersOID), false},
+		{"alias and filters", `SELECT u.* FROM public."users" AS u WHERE id > 1 ORDER BY id;`, userColumns(usersOID), false},
+		{"subquery in where", "select * from public.users where id in (select 1 from logs)", userColumns(usersOID), false},
+		{"ambiguous table", "select * from users", userColumns(usersOID), true},
+		{"join", "select * from public.users join audit.logs on true", userColumns(usersOID), true},
+		{"two tables", "select * from public.users, audit.logs", userColumns(usersOID), true},
+		{"group by", "select id, email from public.users group by id, email", userColumns(usersOID), true},
+		{"union", "select * from public.users union select * from audit.users", userColumns(usersOID), true},
+		{"not a select", "update public.users set email = null", userColumns(usersOID), true},
+		{"function", "select * from generate_series(1, 3)", userColumns(usersOID), true},
+		{"no primary key", "select * from audit.logs", userColumns(usersOID), true},
+		{"unknown table", "select * from public.posts", userColumns(usersOID), true},
+		{"key not selected", "select email from public.users", userColumns(usersOID)[1:], true},
+		{
+			"key renamed",
+			"select id as key, email from public.users",
+			[]db.Column{
+				{Name: "key", TypeOID: 23, TypeName: "int4", TableOID: usersOID, TableAttributeNumber: 1},
+				userColumns(usersOID)[1],
+			},
+			false,
+		},
+		{
+			"expression aliased as the key",
+			"select id + 1 as id, email from public.users",
+			[]db.Column{
+				{Name: "id", TypeOID: 23, TypeName: "int4", TableOID: 0, TableAttributeNumber: 0},
+				userColumns(usersOID)[1],
+			},
+			true,
+		},
+		{"another table's columns", "select * from public.users", userColumns(auditUsersOID), true},
+		{"columns not traced", "select * from public.users", userColumns(0), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := db.NewEditable(test.sql, test.columns, makeEditCatalog())
+			if test.wantErr && !errors.Is(err, db.ErrNotEditable) {
+				t.Fatalf("expected ErrNotEditable, got %v", err)
+			}
+
+			if !test.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEditable_CanEdit(t *testing.T) {
+	t.Parallel()
+
+	columns := append(
+		userColumns(usersOID),
+		db.Column{Name: "email", TypeOID: 25, TypeName: "text", TableOID: 0, TableAttributeNumber: 0},
+		db.Column{Name: "id", TypeOID: 23, TypeName: "int4", TableOID: usersOID, TableAttributeNumber: 1},
+	)
+
+	editable, err := db.NewEditable(
+		"select *, upper(email) as email, id from public.users",
+		columns,
+		makeEditCatalog(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := editable.CanEdit(1); err != nil {
+		t.Fatalf("expected email to be editable, got %v", err)
+	}
+
+	if err := editable.CanEdit(0); !errors.Is(err, db.ErrNotEditable) {
+		t.Fatalf("expected the key not to be editable, got %v", err)
+	}
+
+	if err := editable.CanEdit(2); !errors.Is(err, db.ErrNotEditable) {
+		t.Fatalf("expected an expression aliased as a column not to be editable, got %v", err)
+	}
+
+	if err := editable.CanEdit(3); !errors.Is(err, db.ErrNotEditable) {
+		t.Fatalf("expected the key selected again not to be editable, got %v", err)
+	}
+}
+
+func TestEditable_Update(t *testing.T) {
+	t.Parallel()
+
+	columns := []db.Column{
+		{Name: "key", TypeOID: 23, TypeName: "int4", TableOID: auditUsersOID, TableAttributeNumber: 1},
+		{Name: "mail", TypeOID: 25, TypeName: "text", TableOID: auditUsersOID, TableAttributeNumber: 2},
+	}
+
+	editable, err := db.NewEditable(
+		"select id as key, email as mail from audit.users",
+		columns,
+		makeEditCatalog(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := "o'brien@example.com"
+	row := []any{int32(7), "old@example.com"}
+
+	got := editable.Update(row, 1, &value)
+	want := `UPDATE audit.users SET email = 'o''brien@example.com' WHERE id = 7`
+
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	got = editable.Update(row, 1, nil)
+	if got != "UPDATE audit.users SET email = NULL WHERE id = 7" {
+		t.Fatalf("expected an update to NULL, got %q", got)
+	}
+}
+
+func TestDB_ApplyUpdates(t *testing.T) {
+	t.Parallel()
+
+	t.Run("commits", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+
+		err := database.ApplyUpdates(context.Background(), []string{
+			"update numbers set n = 10 where n = 1",
+			"update numbers set n = 20 where n = 2",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := database.Query(context.Background(), "select sum(n) from numbers")
+		if result.Err != nil || result.Results.Rows[0][0] != int64(42) {
+			t.Fatalf("expected both updates, got %v, %v", result.Results.Rows, result.Err)
+		}
+	})
+
+	t.Run("rolls back unless each changes one row", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+
+		err := database.ApplyUpdates(context.Background(), []string{
+			"update numbers set n = 10 where n = 1",
+			"update numbers set n = 20 where n = 99",
+		})
+		if !errors.Is(err, db.ErrApplyUpdates) || !errors.Is(err, db.ErrRowCount) {
+			t.Fatalf("expected ErrRowCount, got %v", err)
+		}
+
+		result := database.Query(context.Background(), "select sum(n) from numbers")
+		if result.Err != nil || result.Results.Rows[0][0] != int64(15) {
+			t.Fatalf("expected no updates, got %v, %v", result.Results.Rows, result.Err)
+		}
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+
+		err := database.ApplyUpdates(context.Background(), []string{
+			"update numbers set n = 10 where n = 1",
+			"update missing set n = 1",
+		})
+		if !errors.Is(err, db.ErrApplyUpdates) {
+			t.Fatalf("expected ErrApplyUpdates, got %v", err)
+		}
+
+		result := database.Query(context.Background(), "select sum(n) from numbers")
+		if result.Err != nil || result.Results.Rows[0][0] != int64(15) {
+			t.Fatalf("expected no updates, got %v, %v", result.Results.Rows, result.Err)
+		}
+	})
+	t.Run("inside an open transaction", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+		database.Query(context.Background(), "begin")
+
+		err := database.ApplyUpdates(context.Background(), []string{
+			"update numbers set n = 10 where n = 1",
+			"update numbers set n = 20 where n = 99",
+		})
+		if !errors.Is(err, db.ErrRowCount) {
+			t.Fatalf("expected ErrRowCount, got %v", err)
+		}
+
+		result := database.Query(context.Background(), "release savepoint dbq_edits")
+		if result.Err == nil {
+			t.Fatal("expected the savepoint to be released already")
+		}
+
+		err = database.ApplyUpdates(context.Background(), []string{
+			"update numbers set n = 20 where n = 2",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status := database.TxStatus(context.Background()); status != db.TxActive {
+			t.Fatalf("expected the transaction to stay open, got %s", status)
+		}
+
+		database.Query(context.Background(), "rollback")
+
+		result = database.Query(context.Background(), "select sum(n) from numbers")
+		if result.Err != nil || result.Results.Rows[0][0] != int64(15) {
+			t.Fatalf("expected the updates to roll back, got %v, %v",
+				result.Results.Rows, result.Err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrExplain            = errors.New("failed to explain")
+	ErrExplainUnsupported = errors.New("backend does not support explaining queries")
+	ErrExplainAnalyze     = errors.New("backend does not support EXPLAIN ANALYZE")
+)
+
+// Plan is how the database runs a statement.
+type Plan struct {
+	Nodes []PlanNode
+	// Costs is true when nodes carry the planner's estimates.
+	Costs bool
+	// Analyzed is true when the statement ran and nodes carry what was
+	// measured, along with the planning and execution times in
+	// milliseconds.
+	Analyzed      bool
+	PlanningTime  float64
+	ExecutionTime float64
+}
+
+// PlanNode is a step of a plan, fed by its children.
+type PlanNode struct {
+	// Operation is what the step does, like "Seq Scan", and Target what
+	// it does it to, like "on users u".
+	Operation string
+	Target    string
+	// Details are conditions and keys, like "Filter: (id > 1)".
+	Details []string
+
+	StartupCost float64
+	TotalCost   float64
+	PlanRows    float64
+
+	// ActualTime is the milliseconds a loop took to return all its rows,
+	// on average, and ActualRows the rows each loop returned.
+	ActualTime  float64
+	ActualRows  float64
+	ActualLoops float64
+
+	Children []PlanNode
+}
+
+// SelfTime is the milliseconds spent in the node itself, across its
+// loops, leaving out its children's.
+func (node PlanNode) SelfTime() float64 {
+	self := node.ActualTime * node.ActualLoops
+
+	for _, child := range node.Children {
+		self -= child.ActualTime * child.ActualLoops
+	}
+
+	return max(self, 0)
+}
+
+// SelfCost is the node's estimated total cost leaving out its children's.
+func (node PlanNode) SelfCost() float64 {
+	self := node.TotalCost
+
+	for _, child := range node.Children {
+		self -= child.TotalCost
+	}
+
+	return max(self, 0)
+}
+
+// Explainer is implemented by backends that can show how they would run
+// a statement, with args bound to its $1, $2 and so on. With analyze,
+// they run it too.
+type Explainer interface {
+	Explain(ctx context.Context, sql string, analyze bool, args ...any) (Plan, error)
+}
+
+// Explain returns the plan for the single statement in sql, its
+// placeholders bound to their values in params. ANALYZE runs the
+// statement, so it is run in a transaction that is rolled back, or a
+// savepoint when a transaction is open.
+func (db *DB) Explain(ctx context.Context, sql string, analyze bool, params Params) (Plan, error) {
+	explainer, ok := db.inner.(Explainer)
+	if !ok {
+		return Plan{}, ErrExplainUnsupported
+	}
+
+	statements := SplitStatements(sql)
+	if len(statements) != 1 {
+		return Plan{}, fmt.Errorf("%w: expected one statement, got %d", ErrExplain, len(statements))
+	}
+
+	statement, args := Bind(statements[0], params)
+
+	if !analyze {
+		plan, err := explainer.Explain(ctx, statement, false, args...)
+		if err != nil {
+			return Plan{}, fmt.Errorf("%w: %w", ErrExplain, canceled(ctx, err))
+		}
+
+		return plan, nil
+	}
+
+	var plan Plan
+
+	err := db.withSavepoint(ctx, "dbq_explain", false, func() error {
+		var err error
+
+		plan, err = explainer.Explain(ctx, statement, true, args...)
+
+		return err //nolint:wrapcheck // wrapped below
+	})
+	if err != nil {
+		return Plan{}, fmt.Errorf("%w: %w", ErrExplain, canceled(ctx, err))
+	}
+
+	return plan, nil
+}
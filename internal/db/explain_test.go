@@ -0,0 +1,233 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+const analyzedPlan = `[
+  {
+    "Plan": {
+      "Node Type": "Hash Join",
+      "Join Type": "Left",
+      "Startup Cost": 1.5,
+      "Total Cost": 40.25,
+      "Plan Rows": 10,
+      "Actual Total Time": 3.5,
+      "Actual Rows": 8,
+      "Actual Loops": 1,
+      "Hash Cond": "(p.user_id = u.id)",
+      "Plans": [
+        {
+          "Node Type": "Seq Scan",
+          "Relation Name": "posts",
+          "Alias": "p",
+          "Startup Cost": 0,
+          "Total Cost": 20,
+          "Plan Rows": 10,
+          "Actual Total Time": 0.5,
+          "Actual Rows": 8,
+          "Actual Loops": 1,
+          "Filter": "(id > 1)",
+          "Rows Removed by Filter": 2
+        },
+        {
+          "Node Type": "Index Scan",
+          "Index Name": "users_pkey",
+          "Relation Name": "users",
+          "Alias": "users",
+          "Startup Cost": 0.25,
+          "Total Cost": 10,
+          "Plan Rows": 1,
+          "Actual Total Time": 0.25,
+          "Actual Rows": 1,
+          "Actual Loops": 4
+        }
+      ]
+    },
+    "Planning Time": 0.125,
+    "Execution Time": 3.75
+  }
+]`
+
+func TestParsePGPlan(t *testing.T) {
+	t.Parallel()
+
+	plan, err := db.ParsePGPlan([]byte(analyzedPlan))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !plan.Costs || !plan.Analyzed || plan.PlanningTime != 0.125 || plan.ExecutionTime != 3.75 {
+		t.Fatalf("expected an analyzed plan with costs, got %+v", plan)
+	}
+
+	root := plan.Nodes[0]
+	if root.Operation != "Hash Left Join" || root.TotalCost != 40.25 {
+		t.Fatalf("expected the join at the root, got %+v", root)
+	}
+
+	if !slices.Equal(root.Details, []string{"Hash Cond: (p.user_id = u.id)"}) {
+		t.Fatalf("expected the hash condition, got %q", root.Details)
+	}
+
+	scan := root.Children[0]
+	if scan.Operation != "Seq Scan" || scan.Target != "on posts p" {
+		t.Fatalf("expected a scan on posts, got %q %q", scan.Operation, scan.Target)
+	}
+
+	if !slices.Equal(scan.Details, []string{"Filter: (id > 1)", "Rows Removed by Filter: 2"}) {
+		t.Fatalf("expected the filter, got %q", scan.Details)
+	}
+
+	index := root.Children[1]
+	if index.Target != "using users_pkey on users" || index.ActualLoops != 4 {
+		t.Fatalf("expected an index scan, got %+v", index)
+	}
+
+	if root.SelfTime() != 2 {
+		t.Fatalf("expected the join's own time to leave out its children's, got %v", root.SelfTime())
+	}
+
+	if root.SelfCost() != 10.25 {
+		t.Fatalf("expected the join's own cost to leave out its children's, got %v", root.SelfCost())
+	}
+
+	_, err = db.ParsePGPlan([]byte("[]"))
+	if !errors.Is(err, db.ErrExplain) {
+		t.Fatalf("expected ErrExplain, got %v", err)
+	}
+}
+
+func TestDB_Explain(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sqlite", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+
+		plan, err := database.Explain(
+			context.Background(),
+			"select * from numbers where n in (select n from numbers where n > 2);",
+			false,
+			nil,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if plan.Costs || plan.Analyzed || len(plan.Nodes) == 0 {
+			t.Fatalf("expected a plan without costs, got %+v", plan)
+		}
+
+		if !strings.HasPrefix(plan.Nodes[0].Operation, "SCAN numbers") {
+			t.Fatalf("expected a scan of numbers, got %+v", plan.Nodes)
+		}
+	})
+
+	t.Run("sqlite with placeholders", func(t *testing.T) {
+		t.Parallel()
+
+		plan, err := setupNumbers(t).Explain(
+			context.Background(),
+			"select * from numbers where n > :min",
+			false,
+			db.Params{":min": "2"},
+		)
+		if err != nil || len(plan.Nodes) == 0 {
+			t.Fatalf("expected a plan, got %+v, %v", plan, err)
+		}
+	})
+
+	t.Run("sqlite cannot analyze", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+
+		_, err := database.Explain(context.Background(), "delete from numbers", true, nil)
+		if !errors.Is(err, db.ErrExplainAnalyze) {
+			t.Fatalf("expected ErrExplainAnalyze, got %v", err)
+		}
+
+		if status := database.TxStatus(context.Background()); status != db.TxIdle {
+			t.Fatalf("expected the transaction to be rolled back, got %s", status)
+		}
+	})
+
+	t.Run("inside a transaction releases the savepoint", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+		database.Query(context.Background(), "begin")
+
+		_, err := database.Explain(context.Background(), "delete from numbers", true, nil)
+		if !errors.Is(err, db.ErrExplainAnalyze) {
+			t.Fatalf("expected ErrExplainAnalyze, got %v", err)
+		}
+
+		result := database.Query(context.Background(), "release savepoint dbq_explain")
+		if result.Err == nil {
+			t.Fatal("expected the savepoint to be released already")
+		}
+
+		if status := database.TxStatus(context.Background()); status != db.TxActive {
+			t.Fatalf("expected the transaction to stay open, got %s", status)
+		}
+	})
+
+	t.Run("one statement", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+
+		_, err := database.Explain(context.Background(), "select 1; select 2", false, nil)
+		if !errors.Is(err, db.ErrExplain) {
+			t.Fatalf("expected ErrExplain, got %v", err)
+		}
+	})
+
+	t.Run("postgres analyze rolls back", func(t *testing.T) {
+		t.Parallel()
+
+		database := db.NewDB(setupDatabase(t, DSN))
+		database.Query(context.Background(), "create temp table numbers as select 1 as n")
+
+		plan, err := database.Explain(context.Background(), "delete from numbers", true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !plan.Analyzed || plan.Nodes[0].Operation != "Delete" {
+			t.Fatalf("expected an analyzed delete, got %+v", plan)
+		}
+
+		result := database.Query(context.Background(), "select count(*) from numbers")
+		if result.Err != nil || result.Results.Rows[0][0] != int64(1) {
+			t.Fatalf("expected the delete to be rolled back, got %v, %v",
+				result.Results.Rows, result.Err)
+		}
+	})
+
+	t.Run("backends that cannot explain", func(t *testing.T) {
+		t.Parallel()
+
+		mock := &mockPGDB{
+			queryCalled: false,
+			closeCalled: false,
+			results:     db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
+			queryErr:    nil,
+			closeErr:    nil,
+		}
+
+		_, err := db.NewDB(mock).Explain(context.Background(), "select 1", false, nil)
+		if !errors.Is(err, db.ErrExplainUnsupported) {
+			t.Fatalf("expected ErrExplainUnsupported, got %v", err)
+		}
+	})
+}
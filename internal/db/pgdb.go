@@ -2,66 +2,219 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type PGDB struct {
 	conn *pgx.Conn
+	// readOnly rejects the statements that would turn the session's
+	// default_transaction_read_only back off.
+	readOnly bool
 }
 
 var (
-	ErrConnect = errors.New("failed to call Connect")
-	ErrQuery   = errors.New("failed to call Query")
-	ErrValues  = errors.New("failed to call Values")
-	ErrRows    = errors.New("failed to call Rows")
-	ErrClose   = errors.New("failed to call Close")
+	ErrConnect  = errors.New("failed to call Connect")
+	ErrQuery    = errors.New("failed to call Query")
+	ErrValues   = errors.New("failed to call Values")
+	ErrRows     = errors.New("failed to call Rows")
+	ErrClose    = errors.New("failed to call Close")
+	ErrReadOnly = errors.New("read-only session")
 )
 
-func NewPostgresDB(ctx context.Context, dsn string) (PGDB, error) {
-	conn, err := pgx.Connect(ctx, dsn)
+// cancelDeadlineDelay bounds how long a canceled query may keep the
+// connection busy before pgx gives up on the server acknowledging it.
+const cancelDeadlineDelay = 5 * time.Second
+
+func NewPostgresDB(ctx context.Context, dsn string, options Options) (PGDB, error) {
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return PGDB{}, fmt.Errorf("%w: %w", ErrConnect, err)
+	}
+
+	if options.Schema != "" {
+		config.RuntimeParams["search_path"] = QuoteIdentifier(options.Schema) + ", public"
+	}
+
+	if options.ReadOnly {
+		config.RuntimeParams["default_transaction_read_only"] = "on"
+	}
+
+	// send a cancel request to the server when a query's context is canceled
+	// instead of tearing down the connection
+	config.BuildContextWatcherHandler = func(pgConn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{
+			Conn:               pgConn,
+			CancelRequestDelay: 0,
+			DeadlineDelay:      cancelDeadlineDelay,
+		}
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, config)
 	if err != nil {
 		return PGDB{}, fmt.Errorf("%w: %w", ErrConnect, err)
 	}
 
-	db := PGDB{conn: conn}
+	registerRawJSON(conn.TypeMap())
+
+	db := PGDB{conn: conn, readOnly: options.ReadOnly}
 
 	return db, nil
 }
 
-func (db PGDB) Query(ctx context.Context, sql string) (QueryResult, error) {
-	rows, err := db.conn.Query(ctx, sql)
+// registerRawJSON reads json and jsonb values as the text the server
+// sends, rather than decoding them into maps, which would reorder json's
+// keys and round large numbers through float64.
+func registerRawJSON(typeMap *pgtype.Map) {
+	jsonType := &pgtype.Type{
+		Name:  "json",
+		OID:   pgtype.JSONOID,
+		Codec: &pgtype.JSONCodec{Marshal: json.Marshal, Unmarshal: unmarshalRawJSON},
+	}
+	jsonbType := &pgtype.Type{
+		Name:  "jsonb",
+		OID:   pgtype.JSONBOID,
+		Codec: &pgtype.JSONBCodec{Marshal: json.Marshal, Unmarshal: unmarshalRawJSON},
+	}
+
+	typeMap.RegisterType(jsonType)
+	typeMap.RegisterType(jsonbType)
+	typeMap.RegisterType(&pgtype.Type{
+		Name:  "_json",
+		OID:   pgtype.JSONArrayOID,
+		Codec: &pgtype.ArrayCodec{ElementType: jsonType},
+	})
+	typeMap.RegisterType(&pgtype.Type{
+		Name:  "_jsonb",
+		OID:   pgtype.JSONBArrayOID,
+		Codec: &pgtype.ArrayCodec{ElementType: jsonbType},
+	})
+}
+
+// unmarshalRawJSON keeps the text of values read into an any, copied out
+// of pgx's buffer, and unmarshals into anything else as usual.
+func unmarshalRawJSON(data []byte, v any) error {
+	if value, ok := v.(*any); ok {
+		*value = json.RawMessage(slices.Clone(data))
+
+		return nil
+	}
+
+	return json.Unmarshal(data, v) //nolint:wrapcheck // pgx wraps it
+}
+
+func (db PGDB) Query(ctx context.Context, sql string, args ...any) (QueryResult, error) {
+	reader, err := db.Stream(ctx, sql, args...)
+	if err != nil {
+		return QueryResult{}, err
+	}
+
+	return readAll(reader)
+}
+
+// Stream starts sql, leaving its rows to be read off the connection as
+// they are asked for. The connection is busy until they are all read or
+// the reader is closed.
+func (db PGDB) Stream(ctx context.Context, sql string, args ...any) (RowReader, error) {
+	if db.readOnly {
+		if statements := LiftsReadOnly(sql); len(statements) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrReadOnly, statements[0])
+		}
+	}
+
+	rows, err := db.conn.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrQuery, err)
 	}
-	defer rows.Close()
 
-	var results QueryResult
+	return &pgRowReader{
+		rows:    rows,
+		columns: db.columns(rows.FieldDescriptions()),
+	}, nil
+}
 
-	fieldDescriptions := rows.FieldDescriptions()
+func (db PGDB) columns(fieldDescriptions []pgconn.FieldDescription) []Column {
+	columns := make([]Column, len(fieldDescriptions))
 
-	for rows.Next() {
-		values, err := rows.Values()
-		if err != nil {
-			return nil, fmt.Errorf("%w: %w", ErrValues, err)
+	for i, field := range fieldDescriptions {
+		typeName := ""
+		if dataType, ok := db.conn.TypeMap().TypeForOID(field.DataTypeOID); ok {
+			typeName = dataType.Name
 		}
 
-		row := make(map[string]interface{})
-		for i, field := range fieldDescriptions {
-			row[field.Name] = values[i]
+		columns[i] = Column{
+			Name:                 field.Name,
+			TypeOID:              field.DataTypeOID,
+			TypeName:             typeName,
+			TableOID:             field.TableOID,
+			TableAttributeNumber: field.TableAttributeNumber,
 		}
+	}
+
+	return columns
+}
+
+type pgRowReader struct {
+	rows    pgx.Rows
+	columns []Column
+}
+
+func (reader *pgRowReader) Columns() []Column {
+	return reader.columns
+}
+
+func (reader *pgRowReader) Next(n int) ([][]interface{}, bool, error) {
+	var batch [][]interface{}
+
+	for len(batch) < n {
+		if !reader.rows.Next() {
+			err := reader.rows.Err()
+			if err != nil {
+				return nil, true, fmt.Errorf("%w: %w", ErrRows, err)
+			}
 
-		results = append(results, row)
+			return batch, true, nil
+		}
+
+		values, err := reader.rows.Values()
+		if err != nil {
+			return nil, true, fmt.Errorf("%w: %w", ErrValues, err)
+		}
+
+		batch = append(batch, values)
 	}
 
-	rowsErr := rows.Err()
-	if rowsErr != nil {
-		return nil, fmt.Errorf("%w: %w", ErrRows, rowsErr)
+	return batch, false, nil
+}
+
+func (reader *pgRowReader) CommandTag() string {
+	return reader.rows.CommandTag().String()
+}
+
+func (reader *pgRowReader) Close() error {
+	reader.rows.Close()
+
+	return nil
+}
+
+// TxStatus reads the transaction status the server last reported.
+func (db PGDB) TxStatus(_ context.Context) TxStatus {
+	switch db.conn.PgConn().TxStatus() {
+	case 'T':
+		return TxActive
+	case 'E':
+		return TxFailed
 	}
 
-	return results, nil
+	return TxIdle
 }
 
 func (db PGDB) Close(ctx context.Context) error {
@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const pgUserSchemas = `n.nspname not in ('pg_catalog', 'information_schema')
+	and n.nspname not like 'pg_toast%'
+	and n.nspname not like 'pg_temp%'`
+
+const pgSchemasSQL = `
+	select n.nspname
+	from pg_namespace n
+	where ` + pgUserSchemas + `
+	order by 1`
+
+const pgTablesSQL = `
+	select n.nspname, c.relname, c.relkind::text, c.oid
+	from pg_class c
+	join pg_namespace n on n.oid = c.relnamespace
+	where c.relkind in ('r', 'p', 'v', 'm', 'f') and ` + pgUserSchemas + `
+	order by 1, 2`
+
+const pgColumnsSQL = `
+	select n.nspname, c.relname, a.attname, format_type(a.atttypid, a.atttypmod), not a.attnotnull,
+		a.attnum
+	from pg_attribute a
+	join pg_class c on c.oid = a.attrelid
+	join pg_namespace n on n.oid = c.relnamespace
+	where a.attnum > 0 and not a.attisdropped
+		and c.relkind in ('r', 'p', 'v', 'm', 'f') and ` + pgUserSchemas + `
+	order by 1, 2, a.attnum`
+
+const pgIndexesSQL = `
+	select n.nspname, t.relname, i.relname, pg_get_indexdef(i.oid)
+	from pg_index x
+	join pg_class i on i.oid = x.indexrelid
+	join pg_class t on t.oid = x.indrelid
+	join pg_namespace n on n.oid = t.relnamespace
+	where ` + pgUserSchemas + `
+	order by 1, 2, 3`
+
+const pgForeignKeysSQL = `
+	select n.nspname, c.relname, con.conname, pg_get_constraintdef(con.oid)
+	from pg_constraint con
+	join pg_class c on c.oid = con.conrelid
+	join pg_namespace n on n.oid = c.relnamespace
+	where con.contype = 'f' and ` + pgUserSchemas + `
+	order by 1, 2, 3`
+
+const pgPrimaryKeysSQL = `
+	select n.nspname, c.relname, a.attname
+	from pg_index x
+	join pg_class c on c.oid = x.indrelid
+	join pg_namespace n on n.oid = c.relnamespace
+	cross join unnest(x.indkey) with ordinality as k(attnum, position)
+	join pg_attribute a on a.attrelid = c.oid and a.attnum = k.attnum
+	where x.indisprimary and ` + pgUserSchemas + `
+	order by 1, 2, k.position`
+
+const pgFunctionsSQL = `
+	select distinct p.proname
+	from pg_proc p
+	join pg_namespace n on n.oid = p.pronamespace
+	where p.prokind in ('f', 'a', 'w') and n.nspname <> 'information_schema'
+	order by 1`
+
+var pgTableKinds = map[string]TableKind{
+	"r": TableKindTable,
+	"p": TableKindTable,
+	"v": TableKindView,
+	"m": TableKindMaterializedView,
+	"f": TableKindForeignTable,
+}
+
+func (db PGDB) Catalog(ctx context.Context) (Catalog, error) {
+	builder := newCatalogBuilder()
+
+	err := db.eachRow(ctx, pgSchemasSQL, func(rows pgx.Rows) error {
+		var schema string
+
+		err := rows.Scan(&schema)
+		builder.addSchema(schema)
+
+		return err
+	})
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	err = db.eachRow(ctx, pgTablesSQL, func(rows pgx.Rows) error {
+		var (
+			schema, name, kind string
+			oid                uint32
+		)
+
+		err := rows.Scan(&schema, &name, &kind, &oid)
+		builder.addTable(schema, name, pgTableKinds[kind], oid)
+
+		return err
+	})
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	err = db.eachRow(ctx, pgColumnsSQL, func(rows pgx.Rows) error {
+		var column TableColumn
+
+		var schema, table string
+
+		err := rows.Scan(
+			&schema,
+			&table,
+			&column.Name,
+			&column.Type,
+			&column.Nullable,
+			&column.AttributeNumber,
+		)
+		if t := builder.table(schema, table); t != nil {
+			t.Columns = append(t.Columns, column)
+		}
+
+		return err
+	})
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	err = db.eachRow(ctx, pgIndexesSQL, func(rows pgx.Rows) error {
+		var index Index
+
+		var schema, table string
+
+		err := rows.Scan(&schema, &table, &index.Name, &index.Definition)
+		if t := builder.table(schema, table); t != nil {
+			t.Indexes = append(t.Indexes, index)
+		}
+
+		return err
+	})
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	err = db.eachRow(ctx, pgForeignKeysSQL, func(rows pgx.Rows) error {
+		var foreignKey ForeignKey
+
+		var schema, table string
+
+		err := rows.Scan(&schema, &table, &foreignKey.Name, &foreignKey.Definition)
+		if t := builder.table(schema, table); t != nil {
+			t.ForeignKeys = append(t.ForeignKeys, foreignKey)
+		}
+
+		return err
+	})
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	err = db.eachRow(ctx, pgPrimaryKeysSQL, func(rows pgx.Rows) error {
+		var schema, table, column string
+
+		err := rows.Scan(&schema, &table, &column)
+		if t := builder.table(schema, table); t != nil {
+			t.PrimaryKey = append(t.PrimaryKey, column)
+		}
+
+		return err
+	})
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	err = db.eachRow(ctx, pgFunctionsSQL, func(rows pgx.Rows) error {
+		var function string
+
+		err := rows.Scan(&function)
+		builder.catalog.Functions = append(builder.catalog.Functions, function)
+
+		return err
+	})
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	return builder.catalog, nil
+}
+
+func (db PGDB) eachRow(
+	ctx context.Context,
+	sql string,
+	scan func(rows pgx.Rows) error,
+	args ...any,
+) error {
+	rows, err := db.conn.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrQuery, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err := scan(rows)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrValues, err)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRows, err)
+	}
+
+	return nil
+}
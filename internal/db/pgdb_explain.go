@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// pgPlanNode is a node of EXPLAIN (FORMAT JSON) output, keeping the keys
+// shown in the plan tree.
+type pgPlanNode struct {
+	NodeType          string       `json:"Node Type"`
+	Operation         string       `json:"Operation"`
+	JoinType          string       `json:"Join Type"`
+	Strategy          string       `json:"Strategy"`
+	RelationName      string       `json:"Relation Name"`
+	Alias             string       `json:"Alias"`
+	IndexName         string       `json:"Index Name"`
+	CTEName           string       `json:"CTE Name"`
+	FunctionName      string       `json:"Function Name"`
+	IndexCond         string       `json:"Index Cond"`
+	RecheckCond       string       `json:"Recheck Cond"`
+	HashCond          string       `json:"Hash Cond"`
+	MergeCond         string       `json:"Merge Cond"`
+	JoinFilter        string       `json:"Join Filter"`
+	Filter            string       `json:"Filter"`
+	SortKey           []string     `json:"Sort Key"`
+	GroupKey          []string     `json:"Group Key"`
+	StartupCost       float64      `json:"Startup Cost"`
+	TotalCost         float64      `json:"Total Cost"`
+	PlanRows          float64      `json:"Plan Rows"`
+	ActualTotalTime   float64      `json:"Actual Total Time"`
+	ActualRows        float64      `json:"Actual Rows"`
+	ActualLoops       float64      `json:"Actual Loops"`
+	RowsRemovedFilter float64      `json:"Rows Removed by Filter"`
+	Plans             []pgPlanNode `json:"Plans"`
+}
+
+type pgPlan struct {
+	Plan          pgPlanNode `json:"Plan"`
+	PlanningTime  *float64   `json:"Planning Time"`
+	ExecutionTime *float64   `json:"Execution Time"`
+}
+
+func (db PGDB) Explain(ctx context.Context, sql string, analyze bool, args ...any) (Plan, error) {
+	options := "FORMAT JSON"
+	if analyze {
+		options = "ANALYZE, " + options
+	}
+
+	var data []byte
+
+	err := db.eachRow(ctx, "EXPLAIN ("+options+") "+sql, func(rows pgx.Rows) error {
+		return rows.Scan(&data)
+	}, args...)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	return ParsePGPlan(data)
+}
+
+// ParsePGPlan reads the output of EXPLAIN (FORMAT JSON).
+func ParsePGPlan(data []byte) (Plan, error) {
+	var plans []pgPlan
+
+	err := json.Unmarshal(data, &plans)
+	if err != nil {
+		return Plan{}, fmt.Errorf("%w: %w", ErrExplain, err)
+	}
+
+	if len(plans) == 0 {
+		return Plan{}, fmt.Errorf("%w: no plan", ErrExplain)
+	}
+
+	plan := Plan{
+		Nodes:         []PlanNode{plans[0].Plan.node()},
+		Costs:         true,
+		Analyzed:      plans[0].ExecutionTime != nil,
+		PlanningTime:  0,
+		ExecutionTime: 0,
+	}
+
+	if plans[0].PlanningTime != nil {
+		plan.PlanningTime = *plans[0].PlanningTime
+	}
+
+	if plans[0].ExecutionTime != nil {
+		plan.ExecutionTime = *plans[0].ExecutionTime
+	}
+
+	return plan, nil
+}
+
+func (node pgPlanNode) node() PlanNode {
+	children := make([]PlanNode, len(node.Plans))
+	for i, child := range node.Plans {
+		children[i] = child.node()
+	}
+
+	return PlanNode{
+		Operation:   node.operation(),
+		Target:      node.target(),
+		Details:     node.details(),
+		StartupCost: node.StartupCost,
+		TotalCost:   node.TotalCost,
+		PlanRows:    node.PlanRows,
+		ActualTime:  node.ActualTotalTime,
+		ActualRows:  node.ActualRows,
+		ActualLoops: node.ActualLoops,
+		Children:    children,
+	}
+}
+
+// operation names the node the way EXPLAIN's text format does, like
+// "HashAggregate", "Hash Left Join" or "Delete".
+func (node pgPlanNode) operation() string {
+	operation := node.NodeType
+	if operation == "ModifyTable" {
+		operation = node.Operation
+	}
+
+	switch {
+	case node.Strategy == "Hashed":
+		operation = "Hash" + operation
+	case node.Strategy == "Sorted" && node.NodeType == "Aggregate":
+		operation = "Group" + operation
+	case node.Strategy == "Mixed":
+		operation = "Mixed" + operation
+	}
+
+	if node.JoinType != "" && node.JoinType != "Inner" {
+		operation = strings.TrimSuffix(operation, " Join") + " " + node.JoinType + " Join"
+	}
+
+	return operation
+}
+
+func (node pgPlanNode) target() string {
+	var target string
+
+	switch {
+	case node.IndexName != "":
+		target = "using " + node.IndexName
+	case node.CTEName != "":
+		target = "on " + node.CTEName
+	case node.FunctionName != "":
+		target = "on " + node.FunctionName
+	}
+
+	if node.RelationName != "" {
+		target = strings.TrimSpace(target + " on " + node.RelationName)
+	}
+
+	if node.Alias != "" && node.Alias != node.RelationName &&
+		node.Alias != node.CTEName && node.Alias != node.FunctionName {
+		target += " " + node.Alias
+	}
+
+	return target
+}
+
+func (node pgPlanNode) details() []string {
+	var details []string
+
+	add := func(label string, value string) {
+		if value != "" {
+			details = append(details, label+": "+value)
+		}
+	}
+
+	add("Index Cond", node.IndexCond)
+	add("Recheck Cond", node.RecheckCond)
+	add("Hash Cond", node.HashCond)
+	add("Merge Cond", node.MergeCond)
+	add("Join Filter", node.JoinFilter)
+	add("Filter", node.Filter)
+
+	if node.RowsRemovedFilter > 0 {
+		add("Rows Removed by Filter", fmt.Sprint(node.RowsRemovedFilter))
+	}
+
+	add("Sort Key", strings.Join(node.SortKey, ", "))
+	add("Group Key", strings.Join(node.GroupKey, ", "))
+
+	return details
+}
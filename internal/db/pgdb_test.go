@@ -2,8 +2,10 @@ package db_test
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/jshawl/dbq/internal/db"
 )
@@ -15,7 +17,7 @@ func setupDatabase(t *testing.T, dsn string) db.PGDB {
 
 	ctx := context.Background()
 
-	database, err := db.NewPostgresDB(ctx, dsn)
+	database, err := db.NewPostgresDB(ctx, dsn, db.Options{Schema: "", ReadOnly: false})
 	if err != nil {
 		t.Fatalf("setup failed: %v", err)
 	}
@@ -43,11 +45,37 @@ func TestNewPostgresDB(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		t.Parallel()
 
-		_, err := db.NewPostgresDB(t.Context(), "")
+		_, err := db.NewPostgresDB(t.Context(), "", db.Options{Schema: "", ReadOnly: false})
 		if err == nil {
 			t.Fatal("expected error for pgdb Connect")
 		}
 	})
+
+	t.Run("read only", func(t *testing.T) {
+		t.Parallel()
+
+		database, err := db.NewPostgresDB(t.Context(), DSN, db.Options{Schema: "", ReadOnly: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() { _ = database.Close(context.Background()) })
+
+		for _, sql := range []string{
+			"set default_transaction_read_only = off",
+			"begin read write",
+		} {
+			_, err = database.Query(t.Context(), sql)
+			if !errors.Is(err, db.ErrReadOnly) {
+				t.Fatalf("expected %q to be rejected, got %v", sql, err)
+			}
+		}
+
+		_, err = database.Query(t.Context(), "create table read_only_test (id int)")
+		if err == nil {
+			t.Fatal("expected the session to stay read-only")
+		}
+	})
 }
 
 func TestPGDB_Query(t *testing.T) {
@@ -58,28 +86,167 @@ func TestPGDB_Query(t *testing.T) {
 
 		database := setupDatabase(t, DSN)
 
-		want := []map[string]interface{}{
-			{
-				"id":         1,
-				"first_name": "John",
+		want := db.QueryResult{
+			Columns: []db.Column{
+				{Name: "id", TypeOID: 23, TypeName: "int4"},
+				{Name: "first_name", TypeOID: 1043, TypeName: "varchar"},
 			},
-			{
-				"id":         2,
-				"first_name": "Jane",
+			Rows: [][]interface{}{
+				{1, "John"},
+				{2, "Jane"},
 			},
+			CommandTag: "SELECT 2",
 		}
 
-		have, err := database.Query(context.Background(), "SELECT * FROM users")
+		have, err := database.Query(
+			context.Background(),
+			"SELECT id, first_name FROM users ORDER BY id LIMIT 2",
+		)
 		if err != nil {
 			t.Fatalf("%v", err)
 		}
 
-		for i := range want {
-			for k, v := range want[i] {
-				if fmt.Sprintf("%v", have[i][k]) != fmt.Sprintf("%v", v) {
-					t.Errorf("row %d column %s: want %v, got %v", i, k, v, have[i][k])
-				}
-			}
+		assertQueryResult(t, want, have)
+	})
+
+	t.Run("bind arguments", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupDatabase(t, DSN)
+
+		want := db.QueryResult{
+			Columns: []db.Column{
+				{Name: "id", TypeOID: 23, TypeName: "int4"},
+				{Name: "first_name", TypeOID: 1043, TypeName: "varchar"},
+			},
+			Rows: [][]interface{}{
+				{2, "Jane"},
+			},
+			CommandTag: "SELECT 1",
+		}
+
+		// text values go in for any type, leaving the server to parse them
+		have, err := database.Query(
+			context.Background(),
+			"SELECT id, first_name FROM users WHERE id = $1 AND first_name = $2",
+			"2",
+			"Jane",
+		)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		assertQueryResult(t, want, have)
+	})
+
+	t.Run("columns read from a table", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupDatabase(t, DSN)
+
+		have, err := database.Query(
+			context.Background(),
+			"SELECT first_name, id + 1 AS id FROM users LIMIT 1",
+		)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		if have.Columns[0].TableOID == 0 || have.Columns[0].TableAttributeNumber != 4 {
+			t.Fatalf("expected first_name traced to users, got %+v", have.Columns[0])
+		}
+
+		if have.Columns[1].TableOID != 0 || have.Columns[1].TableAttributeNumber != 0 {
+			t.Fatalf("expected an expression not to be traced, got %+v", have.Columns[1])
+		}
+	})
+
+	t.Run("json as stored", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupDatabase(t, DSN)
+
+		have, err := database.Query(
+			context.Background(),
+			`SELECT '{"b": 1, "a": 9007199254740993}'::json, '{"n": 9007199254740993}'::jsonb`,
+		)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		got := []string{
+			db.FormatValue(have.Rows[0][0], have.Columns[0].TypeName),
+			db.FormatValue(have.Rows[0][1], have.Columns[1].TypeName),
+		}
+		want := []string{`{"b":1,"a":9007199254740993}`, `{"n":9007199254740993}`}
+
+		if !slices.Equal(got, want) {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	})
+
+	t.Run("duplicate column names", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupDatabase(t, DSN)
+
+		want := db.QueryResult{
+			Columns: []db.Column{
+				{Name: "id", TypeOID: 23, TypeName: "int4"},
+				{Name: "id", TypeOID: 23, TypeName: "int4"},
+			},
+			Rows: [][]interface{}{
+				{1, 2},
+			},
+			CommandTag: "SELECT 1",
+		}
+
+		have, err := database.Query(
+			context.Background(),
+			"SELECT u.id, p.id FROM users u JOIN posts p ON p.user_id = u.id ORDER BY p.id LIMIT 1 OFFSET 1",
+		)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		assertQueryResult(t, want, have)
+	})
+
+	t.Run("command tag", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupDatabase(t, DSN)
+
+		have, err := database.Query(
+			context.Background(),
+			"UPDATE users SET updated_at = updated_at WHERE id <= 2",
+		)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		if have.CommandTag != "UPDATE 2" {
+			t.Fatalf("want command tag UPDATE 2, got %q", have.CommandTag)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupDatabase(t, DSN)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := database.Query(ctx, "SELECT pg_sleep(10)")
+		if err == nil {
+			t.Fatal("expected error for canceled pgdb Query")
+		}
+
+		// the cancel request leaves the connection usable
+		_, err = database.Query(context.Background(), "SELECT 1")
+		if err != nil {
+			t.Fatalf("expected connection to survive cancellation: %v", err)
 		}
 	})
 
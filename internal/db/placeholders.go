@@ -0,0 +1,182 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Params are the values for a statement's placeholders, keyed by the
+// placeholder as written, like "$1" or ":id". An empty value binds NULL.
+type Params map[string]string
+
+// placeholder is a $1 or :name in sql, from start to end.
+type placeholder struct {
+	text  string
+	start int
+	end   int
+}
+
+// Placeholders returns the $1 and :name placeholders in sql as written,
+// each once in the order they first appear. Those in strings, quoted
+// identifiers and comments, :: casts and the : in array slices like
+// arr[1:n] are not placeholders.
+func Placeholders(sql string) []string {
+	var found []string
+
+	for _, placeholder := range findPlaceholders(sql) {
+		if !slices.Contains(found, placeholder.text) {
+			found = append(found, placeholder.text)
+		}
+	}
+
+	return found
+}
+
+// Bind numbers the placeholders in sql that params has a value for $1,
+// $2 and so on in the order they first appear, and returns the values to
+// bind to them in that order, nil for an empty value. Placeholders
+// without a value are left alone.
+func Bind(sql string, params Params) (string, []any) {
+	var (
+		builder strings.Builder
+		args    []any
+		last    int
+	)
+
+	numbers := map[string]int{}
+
+	for _, placeholder := range findPlaceholders(sql) {
+		value, ok := params[placeholder.text]
+		if !ok {
+			continue
+		}
+
+		number, ok := numbers[placeholder.text]
+		if !ok {
+			var arg any = value
+			if value == "" {
+				arg = nil
+			}
+
+			args = append(args, arg)
+			number = len(args)
+			numbers[placeholder.text] = number
+		}
+
+		builder.WriteString(sql[last:placeholder.start])
+		builder.WriteString(fmt.Sprintf("$%d", number))
+
+		last = placeholder.end
+	}
+
+	builder.WriteString(sql[last:])
+
+	return builder.String(), args
+}
+
+// numberedParams rewrites $1 placeholders as ?1 for SQLite, which takes
+// $1 for a named parameter numbered by where it first appears.
+func numberedParams(sql string) string {
+	var (
+		builder strings.Builder
+		last    int
+	)
+
+	for _, placeholder := range findPlaceholders(sql) {
+		number, ok := strings.CutPrefix(placeholder.text, "$")
+		if !ok {
+			continue
+		}
+
+		builder.WriteString(sql[last:placeholder.start])
+		builder.WriteString("?" + number)
+
+		last = placeholder.end
+	}
+
+	builder.WriteString(sql[last:])
+
+	return builder.String()
+}
+
+func findPlaceholders(sql string) []placeholder {
+	var (
+		found    []placeholder
+		offset   int
+		brackets int
+	)
+
+	for _, tok := range lexSQL(sql) {
+		if tok.kind == tokenCode {
+			var placeholders []placeholder
+
+			placeholders, brackets = codePlaceholders(tok.text, offset, brackets)
+			found = append(found, placeholders...)
+		}
+
+		offset += len(tok.text)
+	}
+
+	return found
+}
+
+// codePlaceholders finds the placeholders in code, brackets deep in [ ]
+// when it starts, and returns them with how deep it is when it ends.
+func codePlaceholders(code string, offset int, brackets int) ([]placeholder, int) {
+	var found []placeholder
+
+	for pos := 0; pos < len(code); pos++ {
+		switch code[pos] {
+		case '[':
+			brackets++
+		case ']':
+			brackets = max(brackets-1, 0)
+		}
+
+		// a : in brackets is an array slice's
+		if pos == len(code)-1 || (brackets > 0 && code[pos] == ':') {
+			continue
+		}
+
+		end := placeholderEnd(code, pos)
+		if end == pos {
+			continue
+		}
+
+		found = append(found, placeholder{
+			text:  code[pos:end],
+			start: offset + pos,
+			end:   offset + end,
+		})
+		pos = end - 1
+	}
+
+	return found, brackets
+}
+
+// placeholderEnd returns where the placeholder starting at pos ends, or
+// pos when none starts there.
+func placeholderEnd(code string, pos int) int {
+	var accepts func(char byte) bool
+
+	switch {
+	case code[pos] == '$' && (pos == 0 || !isIdentifierByte(code[pos-1])):
+		accepts = isDigit
+	case code[pos] == ':' && (pos == 0 || code[pos-1] != ':') && !isDigit(code[pos+1]):
+		accepts = isIdentifierByte
+	default:
+		return pos
+	}
+
+	end := pos + 1
+	for end < len(code) && accepts(code[end]) {
+		end++
+	}
+
+	if end == pos+1 {
+		return pos
+	}
+
+	return end
+}
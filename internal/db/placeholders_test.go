@@ -0,0 +1,72 @@
+package db_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+func TestPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{"none", "select * from users", nil},
+		{"named", "select * from users where id = :id", []string{":id"}},
+		{"numbered", "select * from users where id = $1 and name = $2", []string{"$1", "$2"}},
+		{"repeated", "select :a, $1, :b, :a, $1", []string{":a", "$1", ":b"}},
+		{"adjacent", "select * from t where (id=:user_id)", []string{":user_id"}},
+		{"casts", "select '1'::int, created_at::date, :day::date, $1::int", []string{":day", "$1"}},
+		{"strings", "select ':name', \":name\", $$ :name $1 $$, $tag$ $2 $tag$", nil},
+		{"comments", "-- :name\nselect /* $1 */ 1", nil},
+		{"not placeholders", "select arr[1:2], a$1, $x from t", nil},
+		{
+			"array slices",
+			"select arr[1:n], arr[:hi], m[1][lo:hi], arr[$1:$2] from t where id = :id",
+			[]string{"$1", "$2", ":id"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := db.Placeholders(test.sql)
+			if !slices.Equal(got, test.want) {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	t.Parallel()
+
+	sql, args := db.Bind(
+		"select * from users where id = :id and name = $2 and ':id' <> :id::text and x = :unset",
+		db.Params{":id": "42", "$2": "O'Brien"},
+	)
+
+	want := "select * from users where id = $1 and name = $2 and ':id' <> $1::text and x = :unset"
+	if sql != want {
+		t.Fatalf("expected %q, got %q", want, sql)
+	}
+
+	if !slices.Equal(args, []any{"42", "O'Brien"}) {
+		t.Fatalf("expected the values in placeholder order, got %q", args)
+	}
+
+	sql, args = db.Bind("select $2, $1", db.Params{"$1": "a", "$2": "b"})
+	if sql != "select $1, $2" || !slices.Equal(args, []any{"b", "a"}) {
+		t.Fatalf("expected placeholders renumbered in order, got %q %q", sql, args)
+	}
+
+	sql, args = db.Bind("select :a, :b", db.Params{":a": "", ":b": "b"})
+	if sql != "select $1, $2" || !slices.Equal(args, []any{nil, "b"}) {
+		t.Fatalf("expected an empty value bound as NULL, got %q %q", sql, args)
+	}
+}
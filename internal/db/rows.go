@@ -0,0 +1,352 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// RowReader is a backend's result set left open to be read in batches.
+type RowReader interface {
+	Columns() []Column
+	// Next reads up to n more rows, reporting done once none are left,
+	// after which the reader is closed and CommandTag is known.
+	Next(n int) ([][]interface{}, bool, error)
+	CommandTag() string
+	Close() error
+}
+
+// Streamer is implemented by backends that can read a result a batch at
+// a time rather than all at once.
+type Streamer interface {
+	Stream(ctx context.Context, sql string, args ...any) (RowReader, error)
+}
+
+// Rows is a statement's result being read a batch at a time, so results
+// too big for memory can be shown as they arrive. Reading a query stops
+// at maxRows, leaving the rest of the result unread.
+type Rows struct {
+	// cancel stops the query on the server when rows are abandoned, so
+	// closing does not have to read the rest of the result off the wire.
+	// The rows outlive the ctx they were opened with, which only cancels
+	// the query while Stream or Next is waiting on it.
+	cancel context.CancelFunc
+	// txStatus is the connection's when the rows were opened. Inside a
+	// transaction, closing reads the rest of the result instead, since a
+	// canceled statement would abort the transaction.
+	txStatus  TxStatus
+	reader    RowReader
+	maxRows   int
+	read      int
+	done      bool
+	truncated bool
+}
+
+// Stream starts sql with args bound to its $1, $2 and so on, and returns
+// its rows unread. maxRows caps how many rows of a query can be read;
+// zero means no cap. Statements that may write, like an UPDATE with
+// RETURNING, are read whole up front, since abandoning them part way
+// could undo the write. So are those of backends that cannot stream.
+func (db *DB) Stream(ctx context.Context, sql string, maxRows int, args ...any) (*Rows, error) {
+	query := isQuery(sql)
+	if maxRows <= 0 || !query {
+		maxRows = math.MaxInt
+	}
+
+	var (
+		reader RowReader
+		err    error
+	)
+
+	txStatus := db.TxStatus(ctx)
+	rowsCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+
+	if streamer, ok := db.inner.(Streamer); ok && query {
+		reader, err = streamer.Stream(rowsCtx, sql, args...)
+	} else {
+		var results QueryResult
+
+		results, err = db.inner.Query(rowsCtx, sql, args...)
+		reader = &resultReader{results: results, read: 0}
+	}
+
+	if !stop() && err == nil {
+		_ = reader.Close()
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		err = canceled(ctx, err)
+		cancel()
+
+		return nil, err
+	}
+
+	return &Rows{
+		cancel:    cancel,
+		txStatus:  txStatus,
+		reader:    reader,
+		maxRows:   maxRows,
+		read:      0,
+		done:      false,
+		truncated: false,
+	}, nil
+}
+
+// StreamStatements runs each statement in sql in order like
+// QueryStatements, reading every statement but the last up to maxRows.
+// The last statement's result holds its first batch of rows, and its
+// Rows are returned open when more may follow. Each statement's
+// placeholders are bound to their values in params.
+func (db *DB) StreamStatements(
+	ctx context.Context,
+	sql string,
+	params Params,
+	batchSize int,
+	maxRows int,
+) ([]DBQueryResult, *Rows) {
+	statements := SplitStatements(sql)
+	if len(statements) <= 1 {
+		statements = []string{sql}
+	}
+
+	results := make([]DBQueryResult, 0, len(statements))
+
+	for i, statement := range statements {
+		last := i == len(statements)-1
+		start := time.Now()
+		result := DBQueryResult{
+			Err:       nil,
+			Results:   QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
+			Duration:  0,
+			Query:     statement,
+			Truncated: false,
+		}
+
+		bound, args := Bind(statement, params)
+
+		rows, err := db.Stream(ctx, bound, maxRows, args...)
+		if err != nil {
+			result.Err = err
+			result.Duration = time.Since(start)
+			results = append(results, result)
+
+			break
+		}
+
+		result.Results.Columns = rows.Columns()
+
+		for !rows.Done() && result.Err == nil {
+			var batch [][]interface{}
+
+			batch, result.Err = rows.Next(ctx, batchSize)
+			result.Results.Rows = append(result.Results.Rows, batch...)
+
+			if last {
+				break
+			}
+		}
+
+		result.Results.CommandTag = rows.CommandTag()
+		result.Truncated = rows.Truncated()
+		result.Duration = time.Since(start)
+		results = append(results, result)
+
+		if result.Err != nil {
+			break
+		}
+
+		if last && !rows.Done() {
+			return results, rows
+		}
+	}
+
+	return results, nil
+}
+
+func (rows *Rows) Columns() []Column {
+	return rows.reader.Columns()
+}
+
+// Next reads up to n more rows, canceling the query if ctx is canceled
+// first. Once the result is read, canceled or has reached the row cap,
+// Done reports true and Next returns nothing.
+func (rows *Rows) Next(ctx context.Context, n int) ([][]interface{}, error) {
+	if rows.done {
+		return nil, nil
+	}
+
+	stop := context.AfterFunc(ctx, rows.cancel)
+	defer stop()
+
+	// ask for one past the cap to tell a truncated result from one
+	// that fits exactly
+	remaining := rows.maxRows - rows.read
+	if n > remaining {
+		n = remaining + 1
+	}
+
+	batch, done, err := rows.reader.Next(n)
+	if err != nil {
+		err = canceled(ctx, err)
+		_ = rows.Close()
+
+		return nil, err
+	}
+
+	if len(batch) > remaining {
+		batch = batch[:remaining]
+		rows.truncated = true
+		_ = rows.Close()
+	}
+
+	rows.read += len(batch)
+
+	if done {
+		rows.done = true
+		rows.cancel()
+	}
+
+	return batch, nil
+}
+
+// Done reports whether every row Next will return has been read.
+func (rows *Rows) Done() bool {
+	return rows.done
+}
+
+// Truncated reports whether reading stopped at the row cap with rows
+// left unread.
+func (rows *Rows) Truncated() bool {
+	return rows.truncated
+}
+
+// TxStatus is the connection's transaction status when the rows were
+// opened, which reading them leaves as it was unless reading fails.
+func (rows *Rows) TxStatus() TxStatus {
+	return rows.txStatus
+}
+
+// CommandTag is the statement's completion tag, known once every row
+// has been read. A truncated result has none.
+func (rows *Rows) CommandTag() string {
+	if rows.truncated {
+		return ""
+	}
+
+	return rows.reader.CommandTag()
+}
+
+// Close abandons the rows not yet read.
+func (rows *Rows) Close() error {
+	if rows.done {
+		return nil
+	}
+
+	rows.done = true
+	if rows.txStatus != TxActive {
+		rows.cancel()
+	}
+
+	err := rows.reader.Close()
+	rows.cancel()
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDBClose, err)
+	}
+
+	return nil
+}
+
+// writeWords are those that may make a query write, as in a WITH holding
+// a DELETE or a SELECT INTO.
+//
+//nolint:gochecknoglobals
+var writeWords = map[string]bool{
+	"insert": true, "update": true, "delete": true, "merge": true, "into": true,
+}
+
+// isQuery reports whether sql only reads, so its rows can be left unread.
+func isQuery(sql string) bool {
+	words := Words(sql)
+
+	verb, _ := statementVerb(words)
+	switch verb {
+	case "select", "values", "table", "show":
+	default:
+		return false
+	}
+
+	for _, word := range words {
+		if !word.Quoted && writeWords[word.Text] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func canceled(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	}
+
+	return err
+}
+
+// readAll reads a reader's whole result.
+func readAll(reader RowReader) (QueryResult, error) {
+	results := QueryResult{
+		Columns:    reader.Columns(),
+		Rows:       nil,
+		CommandTag: "",
+	}
+
+	for {
+		batch, done, err := reader.Next(math.MaxInt)
+		if err != nil {
+			_ = reader.Close()
+
+			return QueryResult{}, err
+		}
+
+		results.Rows = append(results.Rows, batch...)
+
+		if done {
+			break
+		}
+	}
+
+	results.CommandTag = reader.CommandTag()
+
+	return results, nil
+}
+
+// resultReader hands out an already read result in batches, for
+// backends that are not Streamers.
+type resultReader struct {
+	results QueryResult
+	read    int
+}
+
+func (reader *resultReader) Columns() []Column {
+	return reader.results.Columns
+}
+
+func (reader *resultReader) Next(n int) ([][]interface{}, bool, error) {
+	end := reader.read + min(n, len(reader.results.Rows)-reader.read)
+	batch := reader.results.Rows[reader.read:end]
+	reader.read = end
+
+	return batch, reader.read == len(reader.results.Rows), nil
+}
+
+func (reader *resultReader) CommandTag() string {
+	return reader.results.CommandTag
+}
+
+func (reader *resultReader) Close() error {
+	return nil
+}
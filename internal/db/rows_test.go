@@ -0,0 +1,351 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+// setupNumbers returns a sqlite database with a numbers table of 1 to 5.
+func setupNumbers(t *testing.T) *db.DB {
+	t.Helper()
+
+	database := db.NewDB(setupSQLiteDatabase(t))
+
+	for _, result := range database.QueryStatements(
+		context.Background(),
+		"create table numbers (n integer); insert into numbers values (1), (2), (3), (4), (5);",
+	) {
+		if result.Err != nil {
+			t.Fatalf("setup failed: %v", result.Err)
+		}
+	}
+
+	return database
+}
+
+func TestDB_Stream(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reads in batches", func(t *testing.T) {
+		t.Parallel()
+
+		rows, err := setupNumbers(t).Stream(context.Background(), "select n from numbers", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sizes []int
+
+		for !rows.Done() {
+			batch, err := rows.Next(context.Background(), 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sizes = append(sizes, len(batch))
+		}
+
+		if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+			t.Fatalf("expected batches of 2, 2 and 1, got %v", sizes)
+		}
+
+		if rows.Truncated() || rows.CommandTag() != "SELECT 5" {
+			t.Fatalf("expected the whole result, got %q", rows.CommandTag())
+		}
+	})
+
+	t.Run("stops at the row cap", func(t *testing.T) {
+		t.Parallel()
+
+		rows, err := setupNumbers(t).Stream(context.Background(), "select n from numbers", 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		batch, err := rows.Next(context.Background(), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(batch) != 3 || !rows.Done() || !rows.Truncated() {
+			t.Fatalf("expected 3 rows and truncation, got %v", batch)
+		}
+
+		if rows.CommandTag() != "" {
+			t.Fatalf("expected no tag for a truncated result, got %q", rows.CommandTag())
+		}
+	})
+
+	t.Run("statements that write are read whole", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+
+		for _, sql := range []string{
+			"update numbers set n = n + 10 returning n",
+			"insert into numbers select n from numbers returning n",
+		} {
+			rows, err := database.Stream(context.Background(), sql, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			batch, err := rows.Next(context.Background(), 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(batch) != 5 || !rows.Done() || rows.Truncated() {
+				t.Fatalf("expected every row of %q, got %v", sql, batch)
+			}
+		}
+
+		rows, err := database.Stream(context.Background(), "update numbers set n = n returning n", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = rows.Next(context.Background(), 20)
+		if err != nil || rows.CommandTag() != "UPDATE 10" {
+			t.Fatalf("expected the statement's own tag, got %q, %v", rows.CommandTag(), err)
+		}
+	})
+
+	t.Run("a result that fits the cap exactly", func(t *testing.T) {
+		t.Parallel()
+
+		rows, err := setupNumbers(t).Stream(context.Background(), "select n from numbers", 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		batch, err := rows.Next(context.Background(), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(batch) != 5 || !rows.Done() || rows.Truncated() {
+			t.Fatalf("expected 5 rows without truncation, got %v", batch)
+		}
+	})
+
+	t.Run("close frees the connection", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+
+		rows, err := database.Stream(context.Background(), "select n from numbers", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = rows.Next(context.Background(), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = rows.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := database.Query(context.Background(), "select count(*) from numbers")
+		if result.Err != nil {
+			t.Fatalf("expected the connection to be free, got %v", result.Err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := setupNumbers(t).Stream(ctx, "select n from numbers", 0)
+		if !errors.Is(err, db.ErrCanceled) {
+			t.Fatalf("expected ErrCanceled, got %v", err)
+		}
+	})
+
+	t.Run("outlive the ctx they were opened with", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		rows, err := setupNumbers(t).Stream(ctx, "select n from numbers", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cancel()
+
+		batch, err := rows.Next(context.Background(), 10)
+		if err != nil || len(batch) != 5 {
+			t.Fatalf("expected 5 rows, got %d, %v", len(batch), err)
+		}
+	})
+
+	t.Run("a WITH that deletes is read whole", func(t *testing.T) {
+		t.Parallel()
+
+		mock := &mockPGDB{
+			queryCalled: false,
+			closeCalled: false,
+			results: db.QueryResult{
+				Columns:    []db.Column{{Name: "n", TypeOID: 23, TypeName: "int4"}},
+				Rows:       [][]interface{}{{1}, {2}, {3}},
+				CommandTag: "SELECT 3",
+			},
+			queryErr: nil,
+			closeErr: nil,
+		}
+
+		rows, err := db.NewDB(mock).Stream(
+			context.Background(),
+			"with gone as (delete from numbers returning n) select n from gone",
+			2,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		batch, err := rows.Next(context.Background(), 10)
+		if err != nil || len(batch) != 3 || rows.Truncated() || rows.CommandTag() != "SELECT 3" {
+			t.Fatalf("expected every row and the tag, got %v, %q, %v", batch, rows.CommandTag(), err)
+		}
+	})
+
+	t.Run("backends that cannot stream", func(t *testing.T) {
+		t.Parallel()
+
+		mock := &mockPGDB{
+			queryCalled: false,
+			closeCalled: false,
+			results: db.QueryResult{
+				Columns:    []db.Column{{Name: "n", TypeOID: 23, TypeName: "int4"}},
+				Rows:       [][]interface{}{{1}, {2}, {3}},
+				CommandTag: "SELECT 3",
+			},
+			queryErr: nil,
+			closeErr: nil,
+		}
+
+		rows, err := db.NewDB(mock).Stream(context.Background(), "select n", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		batch, err := rows.Next(context.Background(), 2)
+		if err != nil || len(batch) != 2 || rows.Done() {
+			t.Fatalf("expected a first batch of 2, got %v, %v", batch, err)
+		}
+
+		batch, err = rows.Next(context.Background(), 2)
+		if err != nil || len(batch) != 1 || !rows.Done() {
+			t.Fatalf("expected the last row, got %v, %v", batch, err)
+		}
+
+		if rows.CommandTag() != "SELECT 3" {
+			t.Fatalf("expected the result's tag, got %q", rows.CommandTag())
+		}
+	})
+}
+
+func TestDB_StreamStatements(t *testing.T) {
+	t.Parallel()
+
+	t.Run("leaves the last statement open", func(t *testing.T) {
+		t.Parallel()
+
+		results, rows := setupNumbers(t).StreamStatements(
+			context.Background(),
+			"select n from numbers; select n from numbers",
+			nil,
+			2,
+			4,
+		)
+
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(results))
+		}
+
+		if len(results[0].Results.Rows) != 4 || !results[0].Truncated {
+			t.Fatalf("expected the first statement read to the cap, got %+v", results[0])
+		}
+
+		if len(results[1].Results.Rows) != 2 || rows == nil {
+			t.Fatalf("expected a first batch and open rows, got %+v", results[1])
+		}
+
+		err := rows.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("a last statement read whole", func(t *testing.T) {
+		t.Parallel()
+
+		results, rows := setupNumbers(t).StreamStatements(
+			context.Background(),
+			"update numbers set n = n + 1",
+			nil,
+			2,
+			4,
+		)
+
+		if rows != nil {
+			t.Fatal("expected no rows left open")
+		}
+
+		if results[0].Results.CommandTag != "UPDATE 5" {
+			t.Fatalf("expected the update's tag, got %q", results[0].Results.CommandTag)
+		}
+	})
+
+	t.Run("binds each statement's placeholders", func(t *testing.T) {
+		t.Parallel()
+
+		results, rows := setupNumbers(t).StreamStatements(
+			context.Background(),
+			"select n from numbers where n > :min; select n from numbers where n = $1 or n = :min",
+			db.Params{":min": "3", "$1": "1"},
+			10,
+			10,
+		)
+
+		if rows != nil || len(results) != 2 {
+			t.Fatalf("expected both statements read, got %+v", results)
+		}
+
+		for i, want := range [][][]interface{}{{{int64(4)}, {int64(5)}}, {{int64(1)}, {int64(3)}}} {
+			if results[i].Err != nil || !reflect.DeepEqual(results[i].Results.Rows, want) {
+				t.Fatalf("expected %v, got %+v", want, results[i])
+			}
+		}
+
+		if results[1].Query != "select n from numbers where n = $1 or n = :min" {
+			t.Fatalf("expected the statement as written, got %q", results[1].Query)
+		}
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		t.Parallel()
+
+		results, rows := setupNumbers(t).StreamStatements(
+			context.Background(),
+			"select 1; not sql; select 2",
+			nil,
+			2,
+			4,
+		)
+
+		if len(results) != 2 || results[1].Err == nil || rows != nil {
+			t.Fatalf("expected the second statement to fail, got %+v", results)
+		}
+	})
+}
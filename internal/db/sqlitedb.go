@@ -0,0 +1,239 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+type SQLiteDB struct {
+	conn *sql.DB
+}
+
+const sqliteScheme = "sqlite://"
+
+func IsSQLiteDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, sqliteScheme) || strings.HasPrefix(dsn, "file:")
+}
+
+// NewSQLiteDB opens the database file. SQLite has a single schema, so
+// options.Schema is ignored.
+func NewSQLiteDB(ctx context.Context, dsn string, options Options) (SQLiteDB, error) {
+	if path, ok := strings.CutPrefix(dsn, sqliteScheme); ok {
+		dsn = "file:" + path
+	}
+
+	if options.ReadOnly {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+
+		dsn += separator + "mode=ro"
+	}
+
+	conn, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return SQLiteDB{}, fmt.Errorf("%w: %w", ErrConnect, err)
+	}
+
+	// a single connection keeps in-memory databases and transactions
+	// consistent across queries
+	conn.SetMaxOpenConns(1)
+
+	err = conn.PingContext(ctx)
+	if err != nil {
+		_ = conn.Close()
+
+		return SQLiteDB{}, fmt.Errorf("%w: %w", ErrConnect, err)
+	}
+
+	return SQLiteDB{conn: conn}, nil
+}
+
+func (db SQLiteDB) Query(ctx context.Context, query string, args ...any) (QueryResult, error) {
+	reader, err := db.Stream(ctx, query, args...)
+	if err != nil {
+		return QueryResult{}, err
+	}
+
+	return readAll(reader)
+}
+
+// Stream starts query, leaving its rows to be stepped through as they
+// are asked for. SQLite's single connection is busy until they are all
+// read or the reader is closed.
+func (db SQLiteDB) Stream(ctx context.Context, query string, args ...any) (RowReader, error) {
+	if len(args) > 0 {
+		query = numberedParams(query)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrQuery, err)
+	}
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		_ = rows.Close()
+
+		return nil, fmt.Errorf("%w: %w", ErrRows, err)
+	}
+
+	columns := make([]Column, len(columnTypes))
+	for i, columnType := range columnTypes {
+		columns[i] = Column{
+			Name:                 columnType.Name(),
+			TypeOID:              0,
+			TypeName:             strings.ToLower(columnType.DatabaseTypeName()),
+			TableOID:             0,
+			TableAttributeNumber: 0,
+		}
+	}
+
+	return &sqliteRowReader{
+		ctx:        ctx,
+		db:         db,
+		query:      query,
+		rows:       rows,
+		columns:    columns,
+		read:       0,
+		commandTag: "",
+	}, nil
+}
+
+type sqliteRowReader struct {
+	ctx        context.Context //nolint:containedctx // for the command tag query once rows are read
+	db         SQLiteDB
+	query      string
+	rows       *sql.Rows
+	columns    []Column
+	read       int
+	commandTag string
+}
+
+func (reader *sqliteRowReader) Columns() []Column {
+	return reader.columns
+}
+
+func (reader *sqliteRowReader) Next(n int) ([][]interface{}, bool, error) {
+	var batch [][]interface{}
+
+	for len(batch) < n {
+		if !reader.rows.Next() {
+			return batch, true, reader.finish()
+		}
+
+		values := make([]interface{}, len(reader.columns))
+		pointers := make([]interface{}, len(reader.columns))
+
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		err := reader.rows.Scan(pointers...)
+		if err != nil {
+			return nil, true, fmt.Errorf("%w: %w", ErrValues, err)
+		}
+
+		batch = append(batch, values)
+		reader.read++
+	}
+
+	return batch, false, nil
+}
+
+// finish closes the rows, freeing the connection to ask for the number
+// of rows changed.
+func (reader *sqliteRowReader) finish() error {
+	err := reader.rows.Err()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRows, err)
+	}
+
+	_ = reader.rows.Close()
+
+	reader.commandTag, err = reader.db.commandTag(reader.ctx, reader.query, reader.read)
+
+	return err
+}
+
+func (reader *sqliteRowReader) CommandTag() string {
+	return reader.commandTag
+}
+
+func (reader *sqliteRowReader) Close() error {
+	err := reader.rows.Close()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrClose, err)
+	}
+
+	return nil
+}
+
+// commandTag builds a Postgres style completion tag, since SQLite does
+// not report one. Comments are skipped and a WITH is tagged by the
+// statement after its common table expressions.
+func (db SQLiteDB) commandTag(ctx context.Context, query string, numRows int) (string, error) {
+	verb, rest := statementVerb(Words(query))
+
+	switch verb {
+	case "":
+		return "", nil
+	case "select", "values", "pragma":
+		return fmt.Sprintf("SELECT %d", numRows), nil
+	case "insert", "update", "delete", "replace":
+		var changes int64
+
+		err := db.conn.QueryRowContext(ctx, "select changes()").Scan(&changes)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrValues, err)
+		}
+
+		if verb == "insert" || verb == "replace" {
+			return fmt.Sprintf("INSERT 0 %d", changes), nil
+		}
+
+		return fmt.Sprintf("%s %d", strings.ToUpper(verb), changes), nil
+	case "create", "drop", "alter":
+		if len(rest) > 0 && !rest[0].Quoted {
+			return strings.ToUpper(verb + " " + rest[0].Text), nil
+		}
+	}
+
+	return strings.ToUpper(verb), nil
+}
+
+// TxStatus asks SQLite whether it is outside autocommit mode. SQLite
+// has no failed state: a statement's error leaves its transaction open.
+func (db SQLiteDB) TxStatus(ctx context.Context) TxStatus {
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return TxIdle
+	}
+	defer conn.Close()
+
+	status := TxIdle
+
+	_ = conn.Raw(func(driverConn any) error {
+		if sqliteConn, ok := driverConn.(*sqlite3.SQLiteConn); ok && !sqliteConn.AutoCommit() {
+			status = TxActive
+		}
+
+		return nil
+	})
+
+	return status
+}
+
+func (db SQLiteDB) Close(_ context.Context) error {
+	err := db.conn.Close()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrClose, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const sqliteSchema = "main"
+
+const sqliteTablesSQL = `
+	select name, type
+	from sqlite_master
+	where type in ('table', 'view') and name not like 'sqlite_%'
+	order by name`
+
+const sqliteColumnsSQL = `select name, type, "notnull" from pragma_table_info(?) order by cid`
+
+const sqlitePrimaryKeySQL = `select name from pragma_table_info(?) where pk > 0 order by pk`
+
+const sqliteIndexesSQL = `
+	select name, coalesce(sql, '')
+	from sqlite_master
+	where type = 'index' and tbl_name = ?
+	order by name`
+
+// sqliteForeignKeysSQL reads each foreign key's columns. References
+// written without columns, like "references users", leave "to" null and
+// point at the parent's primary key.
+const sqliteForeignKeysSQL = `
+	select
+		fk.id,
+		fk."table",
+		group_concat(fk."from", ', '),
+		coalesce(group_concat(coalesce(
+			fk."to",
+			(select name from pragma_table_info(fk."table") where pk = fk.seq + 1)
+		), ', '), '')
+	from pragma_foreign_key_list(?) as fk
+	group by fk.id, fk."table"
+	order by fk.id`
+
+const sqliteFunctionsSQL = `select distinct name from pragma_function_list order by name`
+
+func (db SQLiteDB) Catalog(ctx context.Context) (Catalog, error) {
+	builder := newCatalogBuilder()
+	builder.addSchema(sqliteSchema)
+
+	err := db.eachRow(ctx, sqliteTablesSQL, nil, func(rows *sql.Rows) error {
+		var name, kind string
+
+		err := rows.Scan(&name, &kind)
+		builder.addTable(sqliteSchema, name, TableKind(kind), 0)
+
+		return err
+	})
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	for i := range builder.catalog.Schemas[0].Tables {
+		err := db.describeTable(ctx, &builder.catalog.Schemas[0].Tables[i])
+		if err != nil {
+			return Catalog{}, err
+		}
+	}
+
+	err = db.eachRow(ctx, sqliteFunctionsSQL, nil, func(rows *sql.Rows) error {
+		var function string
+
+		err := rows.Scan(&function)
+		builder.catalog.Functions = append(builder.catalog.Functions, function)
+
+		return err
+	})
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	return builder.catalog, nil
+}
+
+func (db SQLiteDB) describeTable(ctx context.Context, table *Table) error {
+	err := db.eachRow(ctx, sqliteColumnsSQL, []any{table.Name}, func(rows *sql.Rows) error {
+		var (
+			column  TableColumn
+			notNull bool
+		)
+
+		err := rows.Scan(&column.Name, &column.Type, &notNull)
+		column.Type = strings.ToLower(column.Type)
+		column.Nullable = !notNull
+		table.Columns = append(table.Columns, column)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = db.eachRow(ctx, sqlitePrimaryKeySQL, []any{table.Name}, func(rows *sql.Rows) error {
+		var column string
+
+		err := rows.Scan(&column)
+		table.PrimaryKey = append(table.PrimaryKey, column)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = db.eachRow(ctx, sqliteIndexesSQL, []any{table.Name}, func(rows *sql.Rows) error {
+		var index Index
+
+		err := rows.Scan(&index.Name, &index.Definition)
+		table.Indexes = append(table.Indexes, index)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.eachRow(ctx, sqliteForeignKeysSQL, []any{table.Name}, func(rows *sql.Rows) error {
+		var (
+			id                         int
+			refTable, columns, refCols string
+		)
+
+		err := rows.Scan(&id, &refTable, &columns, &refCols)
+		table.ForeignKeys = append(table.ForeignKeys, ForeignKey{
+			Name:       fmt.Sprintf("%s_fkey%d", table.Name, id),
+			Definition: fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s)", columns, refTable, refCols),
+		})
+
+		return err
+	})
+}
+
+func (db SQLiteDB) eachRow(
+	ctx context.Context,
+	query string,
+	args []any,
+	scan func(rows *sql.Rows) error,
+) error {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrQuery, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err := scan(rows)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrValues, err)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRows, err)
+	}
+
+	return nil
+}
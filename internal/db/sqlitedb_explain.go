@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Explain reads EXPLAIN QUERY PLAN, whose steps carry no estimates.
+// SQLite cannot run a statement under EXPLAIN, so analyze is an error.
+func (db SQLiteDB) Explain(
+	ctx context.Context,
+	query string,
+	analyze bool,
+	args ...any,
+) (Plan, error) {
+	if analyze {
+		return Plan{}, ErrExplainAnalyze
+	}
+
+	type step struct {
+		id     int64
+		parent int64
+		detail string
+	}
+
+	var steps []step
+
+	if len(args) > 0 {
+		query = numberedParams(query)
+	}
+
+	err := db.eachRow(ctx, "EXPLAIN QUERY PLAN "+query, args, func(rows *sql.Rows) error {
+		var (
+			current step
+			unused  int64
+		)
+
+		err := rows.Scan(&current.id, &current.parent, &unused, &current.detail)
+		steps = append(steps, current)
+
+		return err
+	})
+	if err != nil {
+		return Plan{}, err
+	}
+
+	// steps come parents first, so each is built from those after it
+	var build func(parent int64) []PlanNode
+
+	build = func(parent int64) []PlanNode {
+		var nodes []PlanNode
+
+		for _, step := range steps {
+			if step.parent != parent || step.id == parent {
+				continue
+			}
+
+			nodes = append(nodes, PlanNode{
+				Operation:   step.detail,
+				Target:      "",
+				Details:     nil,
+				StartupCost: 0,
+				TotalCost:   0,
+				PlanRows:    0,
+				ActualTime:  0,
+				ActualRows:  0,
+				ActualLoops: 0,
+				Children:    build(step.id),
+			})
+		}
+
+		return nodes
+	}
+
+	return Plan{
+		Nodes:         build(0),
+		Costs:         false,
+		Analyzed:      false,
+		PlanningTime:  0,
+		ExecutionTime: 0,
+	}, nil
+}
@@ -0,0 +1,240 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+func setupSQLiteDatabase(t *testing.T) db.SQLiteDB {
+	t.Helper()
+
+	ctx := context.Background()
+
+	database, err := db.NewSQLiteDB(
+		ctx,
+		"sqlite://"+t.TempDir()+"/dbq_test.sqlite3",
+		db.Options{Schema: "", ReadOnly: false},
+	)
+	if err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	t.Cleanup(func() {
+		err := database.Close(ctx)
+		if err != nil {
+			t.Errorf("cleanup failed: %v", err)
+		}
+	})
+
+	for _, statement := range []string{
+		"create table users (id integer primary key, first_name text)",
+		"insert into users (first_name) values ('John'), ('Jane')",
+	} {
+		_, err := database.Query(ctx, statement)
+		if err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	return database
+}
+
+func TestIsSQLiteDSN(t *testing.T) {
+	t.Parallel()
+
+	for dsn, want := range map[string]bool{
+		"sqlite:///tmp/dbq.sqlite3":    true,
+		"file:dbq.sqlite3?mode=ro":     true,
+		"postgres://localhost/dbq":     false,
+		"host=localhost dbname=dbq":    false,
+		"postgresql://sqlite://nested": false,
+	} {
+		if db.IsSQLiteDSN(dsn) != want {
+			t.Errorf("IsSQLiteDSN(%q): want %v", dsn, want)
+		}
+	}
+}
+
+func TestNewSQLiteDB(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupSQLiteDatabase(t)
+		_ = database
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		t.Parallel()
+
+		dsn := "sqlite://" + t.TempDir() + "/dbq_test.sqlite3"
+
+		writable, err := db.NewSQLiteDB(t.Context(), dsn, db.Options{Schema: "", ReadOnly: false})
+		if err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+
+		_ = writable.Close(t.Context())
+
+		readOnly, err := db.NewSQLiteDB(t.Context(), dsn, db.Options{Schema: "", ReadOnly: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		t.Cleanup(func() { _ = readOnly.Close(t.Context()) })
+
+		_, err = readOnly.Query(t.Context(), "create table users (id integer)")
+		if err == nil {
+			t.Fatal("expected read only database to reject writes")
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := db.NewSQLiteDB(
+			t.Context(),
+			"sqlite://"+t.TempDir()+"/missing/dir/dbq.sqlite3",
+			db.Options{Schema: "", ReadOnly: false},
+		)
+		if err == nil {
+			t.Fatal("expected error for sqlite Connect")
+		}
+	})
+}
+
+func TestSQLiteDB_Query(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupSQLiteDatabase(t)
+
+		want := db.QueryResult{
+			Columns: []db.Column{
+				{Name: "id", TypeOID: 0, TypeName: "integer"},
+				{Name: "first_name", TypeOID: 0, TypeName: "text"},
+			},
+			Rows: [][]interface{}{
+				{1, "John"},
+				{2, "Jane"},
+			},
+			CommandTag: "SELECT 2",
+		}
+
+		have, err := database.Query(context.Background(), "SELECT * FROM users")
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		assertQueryResult(t, want, have)
+	})
+
+	t.Run("duplicate column names", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupSQLiteDatabase(t)
+
+		have, err := database.Query(
+			context.Background(),
+			"SELECT a.id, b.id FROM users a JOIN users b ON b.id = a.id + 1",
+		)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		if len(have.Columns) != 2 || have.Columns[0].Name != "id" || have.Columns[1].Name != "id" {
+			t.Fatalf("expected both id columns, got %+v", have.Columns)
+		}
+
+		if fmt.Sprintf("%v", have.Rows[0]) != "[1 2]" {
+			t.Fatalf("expected values in select order, got %v", have.Rows[0])
+		}
+	})
+
+	t.Run("command tags", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupSQLiteDatabase(t)
+
+		for _, test := range []struct {
+			query string
+			want  string
+		}{
+			{query: "insert into users (first_name) values ('Bob'), ('Alice')", want: "INSERT 0 2"},
+			{query: "update users set first_name = upper(first_name)", want: "UPDATE 4"},
+			{query: "delete from users where id = 1", want: "DELETE 1"},
+			{query: "create table posts (id integer)", want: "CREATE TABLE"},
+			{query: "select * from users", want: "SELECT 3"},
+			{query: "-- tidy up\ndelete from users where id = 2", want: "DELETE 1"},
+			{
+				query: "with old as (select id from users where id = 3) delete from users where id in (select id from old)",
+				want:  "DELETE 1",
+			},
+		} {
+			have, err := database.Query(context.Background(), test.query)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if have.CommandTag != test.want {
+				t.Errorf("%s: want command tag %q, got %q", test.query, test.want, have.CommandTag)
+			}
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupSQLiteDatabase(t)
+
+		_, err := database.Query(context.Background(), "! not sql !")
+		if err == nil {
+			t.Fatalf("expected error for sqlite Query")
+		}
+	})
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sqlite", func(t *testing.T) {
+		t.Parallel()
+
+		queryable, err := db.Open(
+			t.Context(),
+			"file:"+t.TempDir()+"/dbq.sqlite3",
+			db.Options{Schema: "", ReadOnly: false},
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok := queryable.(db.SQLiteDB); !ok {
+			t.Fatalf("expected SQLiteDB, got %T", queryable)
+		}
+
+		err = queryable.Close(t.Context())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		queryable, err := db.Open(t.Context(), "", db.Options{Schema: "", ReadOnly: false})
+		if err == nil {
+			t.Fatal("expected error for Open")
+		}
+
+		if queryable != nil {
+			t.Fatalf("expected nil Queryable, got %T", queryable)
+		}
+	})
+}
@@ -0,0 +1,347 @@
+package db
+
+import (
+	"regexp"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenCode tokenKind = iota
+	tokenSemicolon
+	tokenString
+	tokenIdentifier
+	tokenComment
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// SplitStatements splits sql on semicolons that are not inside quotes,
+// dollar-quoted strings or comments. Statements are trimmed and those
+// holding only whitespace or comments are dropped.
+func SplitStatements(sql string) []string {
+	var (
+		statements []string
+		builder    strings.Builder
+		hasCode    bool
+	)
+
+	flush := func() {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(builder.String()))
+		}
+
+		builder.Reset()
+
+		hasCode = false
+	}
+
+	for _, tok := range lexSQL(sql) {
+		if tok.kind == tokenSemicolon {
+			flush()
+
+			continue
+		}
+
+		if tok.kind != tokenComment && strings.TrimSpace(tok.text) != "" {
+			hasCode = true
+		}
+
+		builder.WriteString(tok.text)
+	}
+
+	flush()
+
+	return statements
+}
+
+// Word is a keyword, name or punctuation mark in SQL. Unquoted words
+// are lowercased and quoted names are kept as written, without quotes.
+type Word struct {
+	Text   string
+	Quoted bool
+}
+
+//nolint:gochecknoglobals
+var wordPattern = regexp.MustCompile(`[\pL_][\pL\pN_$]*|\S`)
+
+// Words breaks sql into keywords, names and punctuation. Strings become
+// a single "'" word and comments are dropped.
+func Words(sql string) []Word {
+	var words []Word
+
+	for _, tok := range lexSQL(sql) {
+		switch tok.kind {
+		case tokenCode:
+			for _, text := range wordPattern.FindAllString(tok.text, -1) {
+				words = append(words, Word{Text: strings.ToLower(text), Quoted: false})
+			}
+		case tokenIdentifier:
+			name := strings.TrimSuffix(strings.TrimPrefix(tok.text, `"`), `"`)
+			name = strings.ReplaceAll(name, `""`, `"`)
+			words = append(words, Word{Text: name, Quoted: true})
+		case tokenString:
+			words = append(words, Word{Text: "'", Quoted: false})
+		case tokenSemicolon, tokenComment:
+		}
+	}
+
+	return words
+}
+
+// StatementAt returns the statement around byte offset in sql, bounded
+// by semicolons that are not inside quotes or comments.
+func StatementAt(sql string, offset int) string {
+	start, end, pos := 0, len(sql), 0
+
+	for _, tok := range lexSQL(sql) {
+		if tok.kind == tokenSemicolon {
+			if pos >= offset {
+				end = pos
+
+				break
+			}
+
+			start = pos + 1
+		}
+
+		pos += len(tok.text)
+	}
+
+	return sql[start:end]
+}
+
+// cteVerbs lists what may follow the common table expressions of a WITH.
+//
+//nolint:gochecknoglobals
+var cteVerbs = map[string]bool{
+	"select": true, "insert": true, "update": true, "delete": true, "merge": true, "values": true,
+}
+
+// statementVerb returns the keyword saying what a statement does, looking
+// past any WITH, and the words after it.
+func statementVerb(words []Word) (string, []Word) {
+	if len(words) == 0 || words[0].Quoted {
+		return "", nil
+	}
+
+	if words[0].Text != "with" {
+		return words[0].Text, words[1:]
+	}
+
+	depth := 0
+
+	for i, word := range words {
+		switch {
+		case word.Quoted:
+		case word.Text == "(":
+			depth++
+		case word.Text == ")":
+			depth--
+		case depth == 0 && cteVerbs[word.Text]:
+			return word.Text, words[i+1:]
+		}
+	}
+
+	return "", nil
+}
+
+//nolint:cyclop
+func lexSQL(sql string) []token {
+	var tokens []token
+
+	start := 0
+
+	emit := func(kind tokenKind, end int) {
+		if start < end {
+			tokens = append(tokens, token{kind: kind, text: sql[start:end]})
+		}
+
+		start = end
+	}
+
+	for pos := 0; pos < len(sql); {
+		var end int
+
+		switch {
+		case sql[pos] == ';':
+			emit(tokenCode, pos)
+			emit(tokenSemicolon, pos+1)
+			pos++
+
+			continue
+		case strings.HasPrefix(sql[pos:], "--"):
+			end = lineCommentEnd(sql, pos)
+			emit(tokenCode, pos)
+			emit(tokenComment, end)
+		case strings.HasPrefix(sql[pos:], "/*"):
+			end = blockCommentEnd(sql, pos)
+			emit(tokenCode, pos)
+			emit(tokenComment, end)
+		case sql[pos] == '\'':
+			end = quotedEnd(sql, pos, '\'', isEscapeString(sql, pos))
+			emit(tokenCode, pos)
+			emit(tokenString, end)
+		case sql[pos] == '"':
+			end = quotedEnd(sql, pos, '"', false)
+			emit(tokenCode, pos)
+			emit(tokenIdentifier, end)
+		case sql[pos] == '$':
+			tag, ok := dollarTag(sql, pos)
+			if !ok {
+				pos++
+
+				continue
+			}
+
+			end = dollarQuotedEnd(sql, pos, tag)
+			emit(tokenCode, pos)
+			emit(tokenString, end)
+		default:
+			pos++
+
+			continue
+		}
+
+		pos = end
+	}
+
+	emit(tokenCode, len(sql))
+
+	return tokens
+}
+
+func lineCommentEnd(sql string, pos int) int {
+	end := strings.IndexByte(sql[pos:], '\n')
+	if end < 0 {
+		return len(sql)
+	}
+
+	return pos + end + 1
+}
+
+// blockCommentEnd handles nested block comments like Postgres does.
+func blockCommentEnd(sql string, pos int) int {
+	depth := 0
+
+	for pos < len(sql) {
+		switch {
+		case strings.HasPrefix(sql[pos:], "/*"):
+			depth++
+			pos += 2
+		case strings.HasPrefix(sql[pos:], "*/"):
+			depth--
+			pos += 2
+
+			if depth == 0 {
+				return pos
+			}
+		default:
+			pos++
+		}
+	}
+
+	return len(sql)
+}
+
+// quotedEnd finds the closing quote, treating doubled quotes and, for
+// E'...' strings, backslashes as escapes.
+func quotedEnd(sql string, pos int, quote byte, backslashEscapes bool) int {
+	for pos++; pos < len(sql); pos++ {
+		switch {
+		case backslashEscapes && sql[pos] == '\\':
+			pos++
+		case sql[pos] == quote:
+			if pos+1 < len(sql) && sql[pos+1] == quote {
+				pos++
+
+				continue
+			}
+
+			return pos + 1
+		}
+	}
+
+	return len(sql)
+}
+
+func isEscapeString(sql string, pos int) bool {
+	if pos == 0 || (sql[pos-1] != 'E' && sql[pos-1] != 'e') {
+		return false
+	}
+
+	return pos == 1 || !isIdentifierByte(sql[pos-2])
+}
+
+// dollarTag returns the $tag$ opening a dollar-quoted string at pos. $1
+// style parameters are not tags.
+func dollarTag(sql string, pos int) (string, bool) {
+	if pos > 0 && isIdentifierByte(sql[pos-1]) {
+		return "", false
+	}
+
+	for end := pos + 1; end < len(sql); end++ {
+		if sql[end] == '$' {
+			return sql[pos : end+1], true
+		}
+
+		if !isIdentifierByte(sql[end]) || (end == pos+1 && isDigit(sql[end])) {
+			return "", false
+		}
+	}
+
+	return "", false
+}
+
+func dollarQuotedEnd(sql string, pos int, tag string) int {
+	end := strings.Index(sql[pos+len(tag):], tag)
+	if end < 0 {
+		return len(sql)
+	}
+
+	return pos + len(tag) + end + len(tag)
+}
+
+func isIdentifierByte(char byte) bool {
+	return char == '_' || isDigit(char) || (char|0x20 >= 'a' && char|0x20 <= 'z') || char >= 0x80
+}
+
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+//nolint:gochecknoglobals
+var reservedWords = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true,
+	"as": true, "asc": true, "both": true, "case": true, "cast": true, "check": true,
+	"collate": true, "column": true, "constraint": true, "create": true, "default": true,
+	"desc": true, "distinct": true, "do": true, "else": true, "end": true, "except": true,
+	"false": true, "fetch": true, "for": true, "foreign": true, "from": true, "grant": true,
+	"group": true, "having": true, "in": true, "intersect": true, "into": true, "join": true,
+	"leading": true, "limit": true, "not": true, "null": true, "offset": true, "on": true,
+	"only": true, "or": true, "order": true, "primary": true, "references": true,
+	"select": true, "table": true, "then": true, "to": true, "true": true, "union": true,
+	"unique": true, "user": true, "using": true, "when": true, "where": true, "with": true,
+}
+
+// QuoteIdentifier double quotes name unless it is a plain lowercase
+// identifier that would survive unquoted.
+func QuoteIdentifier(name string) string {
+	plain := name != "" && !isDigit(name[0]) && !reservedWords[name]
+
+	for i := range len(name) {
+		if name[i] != '_' && !isDigit(name[i]) && (name[i] < 'a' || name[i] > 'z') {
+			plain = false
+		}
+	}
+
+	if plain {
+		return name
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,161 @@
+package db_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+func TestSplitStatements(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "single statement",
+			sql:  "select 1",
+			want: []string{"select 1"},
+		},
+		{
+			name: "trailing semicolon",
+			sql:  "select 1;",
+			want: []string{"select 1"},
+		},
+		{
+			name: "multiple statements",
+			sql:  "BEGIN; UPDATE users SET first_name = 'J'; SELECT * FROM users;",
+			want: []string{"BEGIN", "UPDATE users SET first_name = 'J'", "SELECT * FROM users"},
+		},
+		{
+			name: "semicolon in string",
+			sql:  "select 'a;b', 'it''s;'; select 2",
+			want: []string{"select 'a;b', 'it''s;'", "select 2"},
+		},
+		{
+			name: "semicolon in escape string",
+			sql:  `select E'\';'; select 2`,
+			want: []string{`select E'\';'`, "select 2"},
+		},
+		{
+			name: "semicolon in quoted identifier",
+			sql:  `select 1 as "a;b"; select 2`,
+			want: []string{`select 1 as "a;b"`, "select 2"},
+		},
+		{
+			name: "dollar quoting",
+			sql:  "create function f() returns int as $body$ select 1; $body$ language sql; select $$;$$",
+			want: []string{
+				"create function f() returns int as $body$ select 1; $body$ language sql",
+				"select $$;$$",
+			},
+		},
+		{
+			name: "positional parameters are not dollar quotes",
+			sql:  "select $1; select $2",
+			want: []string{"select $1", "select $2"},
+		},
+		{
+			name: "line comments",
+			sql:  "select 1 -- one; two\n; select 2",
+			want: []string{"select 1 -- one; two", "select 2"},
+		},
+		{
+			name: "nested block comments",
+			sql:  "select /* a /* b; */ c; */ 1; select 2",
+			want: []string{"select /* a /* b; */ c; */ 1", "select 2"},
+		},
+		{
+			name: "comment only statements are dropped",
+			sql:  "select 1; -- done\n;  ;",
+			want: []string{"select 1"},
+		},
+		{
+			name: "empty",
+			sql:  "  ",
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := db.SplitStatements(test.sql)
+			if !slices.Equal(got, test.want) {
+				t.Fatalf("want %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestStatementAt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		sql    string
+		offset int
+		want   string
+	}{
+		{"only statement", "select 1", 3, "select 1"},
+		{"middle statement", "select 1; select 2; select 3", 12, " select 2"},
+		{"at a semicolon", "select 1; select 2", 8, "select 1"},
+		{"after a semicolon", "select 1; select 2", 9, " select 2"},
+		{"semicolons in strings", "select ';' as a; select 2", 3, "select ';' as a"},
+		{"semicolons in comments", "select 1 -- ;\n, 2; select 3", 3, "select 1 -- ;\n, 2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := db.StatementAt(test.sql, test.offset)
+			if got != test.want {
+				t.Fatalf("want %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	t.Parallel()
+
+	got := db.Words(`SELECT "Name", 'a;b' FROM s.t -- trailing`)
+	want := []db.Word{
+		{Text: "select", Quoted: false},
+		{Text: "Name", Quoted: true},
+		{Text: ",", Quoted: false},
+		{Text: "'", Quoted: false},
+		{Text: "from", Quoted: false},
+		{Text: "s", Quoted: false},
+		{Text: ".", Quoted: false},
+		{Text: "t", Quoted: false},
+	}
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range map[string]string{
+		"users":       "users",
+		"user_posts2": "user_posts2",
+		"Users":       `"Users"`,
+		"user":        `"user"`,
+		"2fa":         `"2fa"`,
+		"first name":  `"first name"`,
+		`a"b`:         `"a""b"`,
+		"":            `""`,
+	} {
+		if got := db.QuoteIdentifier(name); got != want {
+			t.Errorf("QuoteIdentifier(%q): want %s, got %s", name, want, got)
+		}
+	}
+}
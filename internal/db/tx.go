@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrTxFailed = errors.New("the transaction has failed, roll it back first")
+
+// TxStatus is whether the connection is inside a transaction.
+type TxStatus int
+
+const (
+	TxIdle TxStatus = iota
+	TxActive
+	// TxFailed is a transaction an error has aborted, which ignores
+	// everything until it is rolled back.
+	TxFailed
+)
+
+func (status TxStatus) String() string {
+	switch status {
+	case TxActive:
+		return "in transaction"
+	case TxFailed:
+		return "failed transaction"
+	case TxIdle:
+	}
+
+	return "idle"
+}
+
+// TxStatuser is implemented by backends that can tell whether a
+// transaction is open on their connection.
+type TxStatuser interface {
+	TxStatus(ctx context.Context) TxStatus
+}
+
+// TxStatus reports the connection's transaction status. It waits for the
+// connection when a backend needs it to ask, so must not be called while
+// rows are left open. Backends that cannot tell report TxIdle.
+func (db *DB) TxStatus(ctx context.Context) TxStatus {
+	statuser, ok := db.inner.(TxStatuser)
+	if !ok {
+		return TxIdle
+	}
+
+	return statuser.TxStatus(ctx)
+}
+
+// withSavepoint runs fn in a transaction, or in a savepoint named name
+// when one is already open, and commits what fn did when commit is true
+// and fn succeeds. Otherwise it rolls back, even when ctx is what stopped
+// fn.
+func (db *DB) withSavepoint(ctx context.Context, name string, commit bool, fn func() error) error {
+	begin, end, rollback, release := "BEGIN", "COMMIT", "ROLLBACK", ""
+
+	switch db.TxStatus(ctx) {
+	case TxFailed:
+		return ErrTxFailed
+	case TxActive:
+		begin = "SAVEPOINT " + name
+		end = "RELEASE SAVEPOINT " + name
+		rollback = "ROLLBACK TO SAVEPOINT " + name
+		// rolling back to a savepoint keeps it, so it is released as well
+		release = end
+	case TxIdle:
+	}
+
+	_, err := db.inner.Query(ctx, begin)
+	if err != nil {
+		return err //nolint:wrapcheck // callers wrap it
+	}
+
+	err = fn()
+	if err == nil && commit {
+		_, err = db.inner.Query(ctx, end)
+		if err == nil {
+			return nil
+		}
+	}
+
+	_, rollbackErr := db.inner.Query(context.WithoutCancel(ctx), rollback)
+	if rollbackErr == nil && release != "" {
+		_, rollbackErr = db.inner.Query(context.WithoutCancel(ctx), release)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return rollbackErr //nolint:wrapcheck // callers wrap it
+}
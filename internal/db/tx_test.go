@@ -0,0 +1,104 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+func TestDB_TxStatus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sqlite", func(t *testing.T) {
+		t.Parallel()
+
+		database := setupNumbers(t)
+		if status := database.TxStatus(context.Background()); status != db.TxIdle {
+			t.Fatalf("expected no transaction, got %s", status)
+		}
+
+		database.Query(context.Background(), "begin")
+
+		if status := database.TxStatus(context.Background()); status != db.TxActive {
+			t.Fatalf("expected an open transaction, got %s", status)
+		}
+
+		database.Query(context.Background(), "commit")
+
+		if status := database.TxStatus(context.Background()); status != db.TxIdle {
+			t.Fatalf("expected the transaction to end, got %s", status)
+		}
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		t.Parallel()
+
+		database := db.NewDB(setupDatabase(t, DSN))
+		database.Query(context.Background(), "begin")
+		database.Query(context.Background(), "select 1 / 0")
+
+		if status := database.TxStatus(context.Background()); status != db.TxFailed {
+			t.Fatalf("expected a failed transaction, got %s", status)
+		}
+
+		database.Query(context.Background(), "rollback")
+
+		if status := database.TxStatus(context.Background()); status != db.TxIdle {
+			t.Fatalf("expected the transaction to end, got %s", status)
+		}
+	})
+
+	t.Run("backends that cannot tell", func(t *testing.T) {
+		t.Parallel()
+
+		mock := &mockPGDB{
+			queryCalled: false,
+			closeCalled: false,
+			results:     db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
+			queryErr:    nil,
+			closeErr:    nil,
+		}
+
+		if status := db.NewDB(mock).TxStatus(context.Background()); status != db.TxIdle {
+			t.Fatalf("expected idle, got %s", status)
+		}
+	})
+}
+
+func TestDB_Stream_InTransaction(t *testing.T) {
+	t.Parallel()
+
+	database := setupNumbers(t)
+	database.Query(context.Background(), "begin")
+	database.Query(context.Background(), "delete from numbers where n = 5")
+
+	rows, err := database.Stream(context.Background(), "select n from numbers", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rows.TxStatus() != db.TxActive {
+		t.Fatalf("expected the rows to know the transaction is open, got %s", rows.TxStatus())
+	}
+
+	_, err = rows.Next(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = rows.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := database.Query(context.Background(), "commit")
+	if result.Err != nil {
+		t.Fatalf("expected the transaction to survive closing, got %v", result.Err)
+	}
+
+	result = database.Query(context.Background(), "select count(*) from numbers")
+	if result.Results.Rows[0][0] != int64(4) {
+		t.Fatalf("expected the delete to be committed, got %v", result.Results.Rows)
+	}
+}
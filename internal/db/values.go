@@ -0,0 +1,214 @@
+package db
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatValue renders a value from a result row in Postgres's text form,
+// e.g. a uuid as 8-4-4-4-12 hex rather than a byte array, numerics as
+// digits and arrays as {1,2,3}. typeName is the column's Column.TypeName,
+// needed to tell dates, timestamps and JSON apart. NULL is the empty
+// string; callers that need to tell NULL apart should check for nil.
+func FormatValue(value any, typeName string) string {
+	if value != nil && IsJSONType(typeName) {
+		return formatJSON(value)
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case bool:
+		if value {
+			return "t"
+		}
+
+		return "f"
+	case int:
+		return strconv.Itoa(value)
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", value)
+	case float32:
+		return formatFloat(float64(value), 32) //nolint:mnd
+	case float64:
+		return formatFloat(value, 64) //nolint:mnd
+	case []byte:
+		return `\x` + hex.EncodeToString(value)
+	case [16]byte:
+		return formatUUID(value)
+	case time.Time:
+		return formatTime(value, typeName)
+	case netip.Prefix:
+		if value.IsSingleIP() {
+			return value.Addr().String()
+		}
+
+		return value.String()
+	case []any:
+		return formatArray(value, strings.TrimPrefix(typeName, "_"))
+	case map[string]any:
+		return formatJSON(value)
+	case driver.Valuer:
+		// pgtype's numeric, interval and time types know their text form
+		text, err := value.Value()
+		if err == nil {
+			return FormatValue(text, typeName)
+		}
+	case fmt.Stringer:
+		return value.String()
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// IsJSONType reports whether typeName is json or jsonb.
+func IsJSONType(typeName string) bool {
+	return typeName == "json" || typeName == "jsonb"
+}
+
+// IsNumber reports whether value renders as a plain number, which JSON
+// and SQL can both take unquoted. NaN and the infinities cannot.
+func IsNumber(value any, typeName string) bool {
+	switch value := value.(type) {
+	case bool:
+		return false
+	case float32:
+		return !math.IsNaN(float64(value)) && !math.IsInf(float64(value), 0)
+	case float64:
+		return !math.IsNaN(value) && !math.IsInf(value, 0)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	}
+
+	return typeName == "numeric" && json.Valid([]byte(FormatValue(value, typeName)))
+}
+
+// Literal renders a value as a SQL literal: NULL, a boolean, a plain
+// number or its text form quoted as a string.
+func Literal(value any, typeName string) string {
+	switch value := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if value {
+			return "TRUE"
+		}
+
+		return "FALSE"
+	}
+
+	text := FormatValue(value, typeName)
+	if IsNumber(value, typeName) {
+		return text
+	}
+
+	return QuoteString(text)
+}
+
+// QuoteString single quotes text as a SQL string literal.
+func QuoteString(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
+
+func formatFloat(value float64, bitSize int) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "Infinity"
+	case math.IsInf(value, -1):
+		return "-Infinity"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, bitSize)
+}
+
+func formatUUID(value [16]byte) string {
+	encoded := hex.EncodeToString(value[:])
+
+	return strings.Join([]string{
+		encoded[0:8], encoded[8:12], encoded[12:16], encoded[16:20], encoded[20:32],
+	}, "-")
+}
+
+func formatTime(value time.Time, typeName string) string {
+	switch typeName {
+	case "date":
+		return value.Format(time.DateOnly)
+	case "timestamp", "datetime":
+		return value.Format("2006-01-02 15:04:05.999999")
+	}
+
+	if _, offset := value.Zone(); offset%3600 != 0 {
+		return value.Format("2006-01-02 15:04:05.999999-07:00")
+	}
+
+	return value.Format("2006-01-02 15:04:05.999999-07")
+}
+
+// formatJSON renders JSON on one line. Text read from the server is only
+// compacted, keeping its keys in order and its numbers as written.
+func formatJSON(value any) string {
+	var buffer bytes.Buffer
+
+	if raw, ok := value.(json.RawMessage); ok {
+		err := json.Compact(&buffer, raw)
+		if err != nil {
+			return string(raw)
+		}
+
+		return buffer.String()
+	}
+
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// formatArray renders an array literal, quoting elements the way
+// Postgres does.
+func formatArray(values []any, elementType string) string {
+	elements := make([]string, len(values))
+
+	for i, value := range values {
+		switch value := value.(type) {
+		case nil:
+			elements[i] = "NULL"
+		case []any:
+			elements[i] = formatArray(value, elementType)
+		default:
+			elements[i] = quoteArrayElement(FormatValue(value, elementType))
+		}
+	}
+
+	return "{" + strings.Join(elements, ",") + "}"
+}
+
+func quoteArrayElement(element string) string {
+	plain := element != "" &&
+		!strings.EqualFold(element, "NULL") &&
+		!strings.ContainsAny(element, "{},\"\\ \t\n")
+	if plain {
+		return element
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+	return `"` + replacer.Replace(element) + `"`
+}
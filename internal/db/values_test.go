@@ -0,0 +1,107 @@
+package db_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jshawl/dbq/internal/db"
+)
+
+func TestFormatValue(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2025, 9, 21, 15, 41, 22, 123000000, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    any
+		typeName string
+		want     string
+	}{
+		{"null", nil, "text", ""},
+		{"text", "hello", "text", "hello"},
+		{"bool", true, "bool", "t"},
+		{"int", int32(42), "int4", "42"},
+		{"float", 1.5, "float8", "1.5"},
+		{"large float", 1e20, "float8", "1e+20"},
+		{"nan", math.NaN(), "float8", "NaN"},
+		{"bytea", []byte("hi"), "bytea", `\x6869`},
+		{
+			"uuid",
+			[16]byte{
+				0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3,
+				0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00,
+			},
+			"uuid",
+			"123e4567-e89b-12d3-a456-426614174000",
+		},
+		{
+			"numeric",
+			pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, NaN: false, InfinityModifier: 0, Valid: true},
+			"numeric",
+			"123.45",
+		},
+		{"date", time.Date(2025, 9, 21, 0, 0, 0, 0, time.UTC), "date", "2025-09-21"},
+		{"timestamp", at, "timestamp", "2025-09-21 15:41:22.123"},
+		{"timestamptz", at, "timestamptz", "2025-09-21 15:41:22.123+00"},
+		{
+			"timestamptz half hour",
+			at.In(time.FixedZone("IST", 19800)),
+			"timestamptz",
+			"2025-09-21 21:11:22.123+05:30",
+		},
+		{"inet", netip.MustParsePrefix("10.0.0.1/32"), "inet", "10.0.0.1"},
+		{"cidr", netip.MustParsePrefix("10.0.0.0/8"), "cidr", "10.0.0.0/8"},
+		{"array", []any{int32(1), nil, int32(3)}, "_int4", "{1,NULL,3}"},
+		{"text array", []any{"a b", `q"t`, "", "plain"}, "_text", `{"a b","q\"t","",plain}`},
+		{"nested array", []any{[]any{int32(1), int32(2)}, []any{int32(3), int32(4)}}, "_int4", "{{1,2},{3,4}}"},
+		{"jsonb", map[string]any{"b": []any{1.0, "<x>"}, "a": nil}, "jsonb", `{"a":null,"b":[1,"<x>"]}`},
+		{"json string", "quoted", "json", `"quoted"`},
+		{"json text", json.RawMessage(`{"b": 1, "a": 9007199254740993}`), "json", `{"b":1,"a":9007199254740993}`},
+		{"json array", []any{json.RawMessage(`{"b": 1}`), nil}, "_json", `{"{\"b\":1}",NULL}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := db.FormatValue(test.value, test.typeName)
+			if got != test.want {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		value    any
+		typeName string
+		want     string
+	}{
+		{"null", nil, "text", "NULL"},
+		{"bool", false, "bool", "FALSE"},
+		{"int", int64(42), "int8", "42"},
+		{"nan", math.NaN(), "float8", "'NaN'"},
+		{"text", "it's", "text", "'it''s'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := db.Literal(test.value, test.typeName)
+			if got != test.want {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jshawl/dbq/internal/db"
+)
+
+type Format string
+
+const (
+	FormatTable    Format = "table"
+	FormatCSV      Format = "csv"
+	FormatTSV      Format = "tsv"
+	FormatJSON     Format = "json"
+	FormatNDJSON   Format = "ndjson"
+	FormatMarkdown Format = "markdown"
+	FormatSQL      Format = "sql"
+)
+
+// defaultInsertTable names the table INSERT statements target when Write
+// is not given one.
+const defaultInsertTable = "results"
+
+// filePerms are what os.Create gives files, before the umask.
+const filePerms = 0o666
+
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	ErrWrite         = errors.New("failed to write results")
+	ErrExists        = errors.New("file already exists")
+)
+
+// Formats lists every format, in the order they are offered.
+func Formats() []Format {
+	return []Format{
+		FormatTable,
+		FormatCSV,
+		FormatTSV,
+		FormatJSON,
+		FormatNDJSON,
+		FormatMarkdown,
+		FormatSQL,
+	}
+}
+
+// ForPath picks the format for a file from its extension.
+func ForPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt":
+		return FormatTable, nil
+	case ".csv":
+		return FormatCSV, nil
+	case ".tsv":
+		return FormatTSV, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	case ".md", ".markdown":
+		return FormatMarkdown, nil
+	case ".sql":
+		return FormatSQL, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+}
+
+func Parse(name string) (Format, error) {
+	for _, format := range Formats() {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+}
+
+// Write writes results to w. Columns keep their SELECT order in every
+// format, including JSON objects.
+func Write(w io.Writer, format Format, results db.QueryResult) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, results)
+	case FormatCSV:
+		return writeCSV(w, results)
+	case FormatTSV:
+		return writeTSV(w, results)
+	case FormatJSON:
+		return writeJSON(w, results)
+	case FormatNDJSON:
+		return writeNDJSON(w, results)
+	case FormatMarkdown:
+		return writeMarkdown(w, results)
+	case FormatSQL:
+		return WriteInserts(w, results, defaultInsertTable)
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+// WriteFile writes results to path in the format its extension names. SQL
+// files insert into a table named after the file, e.g. users.sql into
+// users. A file already at path is only replaced when overwrite is true,
+// and is otherwise left alone with ErrExists.
+func WriteFile(path string, results db.QueryResult, overwrite bool) error {
+	outputFormat, err := ForPath(path)
+	if err != nil {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(path, flags, filePerms)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w: %s", ErrExists, path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	if outputFormat == FormatSQL {
+		table := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		err = WriteInserts(file, results, table)
+	} else {
+		err = Write(file, outputFormat, results)
+	}
+
+	closeErr := file.Close()
+	if err == nil && closeErr != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, closeErr)
+	}
+
+	return err
+}
+
+func writeTable(w io.Writer, results db.QueryResult) error {
+	_, err := io.WriteString(w, Table(results, 0))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	return nil
+}
+
+func writeCSV(w io.Writer, results db.QueryResult) error {
+	writer := csv.NewWriter(w)
+
+	header := make([]string, len(results.Columns))
+	for i, column := range results.Columns {
+		header[i] = column.Name
+	}
+
+	err := writer.Write(header)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	for _, row := range results.Rows {
+		record := make([]string, len(row))
+
+		for i, value := range row {
+			record[i] = db.FormatValue(value, results.Columns[i].TypeName)
+		}
+
+		err := writer.Write(record)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrWrite, err)
+		}
+	}
+
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	return nil
+}
+
+func writeTSV(w io.Writer, results db.QueryResult) error {
+	var builder strings.Builder
+
+	header := make([]string, len(results.Columns))
+	for i, column := range results.Columns {
+		header[i] = tsvField(column.Name)
+	}
+
+	builder.WriteString(strings.Join(header, "\t") + "\n")
+
+	for _, row := range results.Rows {
+		builder.WriteString(TSVRow(results.Columns, row) + "\n")
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	return nil
+}
+
+// TSVRow renders a row as tab separated values, escaping tabs, newlines
+// and backslashes the way COPY's text format does so pasted rows keep
+// their shape. NULL is an empty field.
+func TSVRow(columns []db.Column, row []interface{}) string {
+	fields := make([]string, len(row))
+	for i, value := range row {
+		fields[i] = tsvField(db.FormatValue(value, columns[i].TypeName))
+	}
+
+	return strings.Join(fields, "\t")
+}
+
+func tsvField(field string) string {
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(field)
+}
+
+// RowJSON renders a row as a JSON object with keys in column order, as
+// the json and ndjson formats do.
+func RowJSON(columns []db.Column, row []interface{}) string {
+	return string(jsonObject(columns, row))
+}
+
+// RowCount describes a number of rows, like "1 row" or "3 rows".
+func RowCount(rows int) string {
+	if rows == 1 {
+		return "1 row"
+	}
+
+	return fmt.Sprintf("%d rows", rows)
+}
+
+func writeJSON(w io.Writer, results db.QueryResult) error {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("[")
+
+	for i, row := range results.Rows {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+
+		buffer.WriteString("\n  ")
+		buffer.Write(jsonObject(results.Columns, row))
+	}
+
+	if len(results.Rows) > 0 {
+		buffer.WriteString("\n")
+	}
+
+	buffer.WriteString("]\n")
+
+	_, err := buffer.WriteTo(w)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	return nil
+}
+
+func writeNDJSON(w io.Writer, results db.QueryResult) error {
+	for _, row := range results.Rows {
+		_, err := w.Write(append(jsonObject(results.Columns, row), '\n'))
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrWrite, err)
+		}
+	}
+
+	return nil
+}
+
+// jsonObject encodes a row as an object with keys in column order, which
+// encoding a map would not keep.
+func jsonObject(columns []db.Column, row []interface{}) []byte {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("{")
+
+	for i, column := range columns {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+
+		key, _ := json.Marshal(column.Name)
+		buffer.Write(key)
+		buffer.WriteString(":")
+		buffer.Write(jsonValue(row[i], column.TypeName))
+	}
+
+	buffer.WriteString("}")
+
+	return buffer.Bytes()
+}
+
+// jsonValue encodes value as its JSON counterpart: numbers, booleans,
+// arrays and json columns natively and everything else as a string of
+// its Postgres text form.
+func jsonValue(value interface{}, typeName string) []byte {
+	switch value := value.(type) {
+	case nil:
+		return []byte("null")
+	case []any:
+		if db.IsJSONType(typeName) {
+			break
+		}
+
+		elements := make([][]byte, len(value))
+		for i, element := range value {
+			elements[i] = jsonValue(element, strings.TrimPrefix(typeName, "_"))
+		}
+
+		return append(append([]byte("["), bytes.Join(elements, []byte(","))...), ']')
+	}
+
+	text := db.FormatValue(value, typeName)
+	if db.IsJSONType(typeName) || db.IsNumber(value, typeName) {
+		return []byte(text)
+	}
+
+	encoded, _ := json.Marshal(text)
+
+	return encoded
+}
+
+func writeMarkdown(w io.Writer, results db.QueryResult) error {
+	var builder strings.Builder
+
+	header := make([]string, len(results.Columns))
+	separator := make([]string, len(results.Columns))
+
+	for i, column := range results.Columns {
+		header[i] = markdownCell(column.Name)
+		separator[i] = "---"
+	}
+
+	builder.WriteString(markdownRow(header))
+	builder.WriteString(markdownRow(separator))
+
+	for _, row := range results.Rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = markdownCell(db.FormatValue(value, results.Columns[i].TypeName))
+		}
+
+		builder.WriteString(markdownRow(cells))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	return nil
+}
+
+func markdownRow(cells []string) string {
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
+
+func markdownCell(cell string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(cell)
+}
+
+// WriteInserts writes one INSERT statement per row into table, which may
+// be schema qualified.
+func WriteInserts(w io.Writer, results db.QueryResult, table string) error {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = db.QuoteIdentifier(part)
+	}
+
+	columns := make([]string, len(results.Columns))
+	for i, column := range results.Columns {
+		columns[i] = db.QuoteIdentifier(column.Name)
+	}
+
+	prefix := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (",
+		strings.Join(parts, "."),
+		strings.Join(columns, ", "),
+	)
+
+	var builder strings.Builder
+
+	for _, row := range results.Rows {
+		values := make([]string, len(row))
+		for i, value := range row {
+			values[i] = db.Literal(value, results.Columns[i].TypeName)
+		}
+
+		builder.WriteString(prefix + strings.Join(values, ", ") + ");\n")
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,356 @@
+package format_test
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jshawl/dbq/internal/db"
+	"github.com/jshawl/dbq/internal/format"
+)
+
+func makeResults() db.QueryResult {
+	return db.QueryResult{
+		Columns: []db.Column{
+			{Name: "name", TypeOID: 25, TypeName: "text"},
+			{Name: "id", TypeOID: 23, TypeName: "int4"},
+			{Name: "email", TypeOID: 25, TypeName: "text"},
+		},
+		Rows: [][]interface{}{
+			{"Jane, Jr.", int32(1), nil},
+			{"John", int32(22), "john@example.com"},
+		},
+		CommandTag: "SELECT 2",
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format format.Format
+		want   string
+	}{
+		{
+			format: format.FormatTable,
+			want: strings.Join([]string{
+				" name      | id | email",
+				"-----------+----+------------------",
+				" Jane, Jr. |  1 |",
+				" John      | 22 | john@example.com",
+				"",
+			}, "\n"),
+		},
+		{
+			format: format.FormatCSV,
+			want:   "name,id,email\n\"Jane, Jr.\",1,\nJohn,22,john@example.com\n",
+		},
+		{
+			format: format.FormatTSV,
+			want:   "name\tid\temail\nJane, Jr.\t1\t\nJohn\t22\tjohn@example.com\n",
+		},
+		{
+			format: format.FormatJSON,
+			want: "[\n" +
+				`  {"name":"Jane, Jr.","id":1,"email":null},` + "\n" +
+				`  {"name":"John","id":22,"email":"john@example.com"}` + "\n" +
+				"]\n",
+		},
+		{
+			format: format.FormatNDJSON,
+			want: `{"name":"Jane, Jr.","id":1,"email":null}` + "\n" +
+				`{"name":"John","id":22,"email":"john@example.com"}` + "\n",
+		},
+		{
+			format: format.FormatMarkdown,
+			want: "| name | id | email |\n" +
+				"| --- | --- | --- |\n" +
+				"| Jane, Jr. | 1 |  |\n" +
+				"| John | 22 | john@example.com |\n",
+		},
+		{
+			format: format.FormatSQL,
+			want: "INSERT INTO results (name, id, email) VALUES ('Jane, Jr.', 1, NULL);\n" +
+				"INSERT INTO results (name, id, email) VALUES ('John', 22, 'john@example.com');\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.format), func(t *testing.T) {
+			t.Parallel()
+
+			var builder strings.Builder
+
+			err := format.Write(&builder, test.format, makeResults())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if builder.String() != test.want {
+				t.Fatalf("expected:\n%s\ngot:\n%s", test.want, builder.String())
+			}
+		})
+	}
+
+	t.Run("empty json", func(t *testing.T) {
+		t.Parallel()
+
+		var builder strings.Builder
+
+		err := format.Write(&builder, format.FormatJSON, db.QueryResult{
+			Columns:    makeResults().Columns,
+			Rows:       nil,
+			CommandTag: "SELECT 0",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if builder.String() != "[]\n" {
+			t.Fatalf("expected empty array, got %q", builder.String())
+		}
+	})
+}
+
+func TestWrite_Types(t *testing.T) {
+	t.Parallel()
+
+	results := db.QueryResult{
+		Columns: []db.Column{
+			{Name: "id", TypeOID: 2950, TypeName: "uuid"},
+			{Name: "price", TypeOID: 1700, TypeName: "numeric"},
+			{Name: "tags", TypeOID: 1009, TypeName: "_text"},
+			{Name: "data", TypeOID: 3802, TypeName: "jsonb"},
+			{Name: "note", TypeOID: 25, TypeName: "text"},
+		},
+		Rows: [][]interface{}{{
+			[16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00},
+			pgtype.Numeric{Int: big.NewInt(1999), Exp: -2, NaN: false, InfinityModifier: 0, Valid: true},
+			[]any{"a", "b c"},
+			map[string]any{"k": "v"},
+			"it's | here",
+		}},
+		CommandTag: "SELECT 1",
+	}
+
+	tests := []struct {
+		format format.Format
+		want   string
+	}{
+		{
+			format: format.FormatNDJSON,
+			want: `{"id":"123e4567-e89b-12d3-a456-426614174000","price":19.99,` +
+				`"tags":["a","b c"],"data":{"k":"v"},"note":"it's | here"}` + "\n",
+		},
+		{
+			format: format.FormatCSV,
+			want: "id,price,tags,data,note\n" +
+				`123e4567-e89b-12d3-a456-426614174000,19.99,"{a,""b c""}","{""k"":""v""}",it's | here` + "\n",
+		},
+		{
+			format: format.FormatSQL,
+			want: "INSERT INTO results (id, price, tags, data, note) VALUES (" +
+				`'123e4567-e89b-12d3-a456-426614174000', 19.99, '{a,"b c"}', '{"k":"v"}', 'it''s | here');` + "\n",
+		},
+		{
+			format: format.FormatMarkdown,
+			want: "| id | price | tags | data | note |\n| --- | --- | --- | --- | --- |\n" +
+				`| 123e4567-e89b-12d3-a456-426614174000 | 19.99 | {a,"b c"} | {"k":"v"} | it's \| here |` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.format), func(t *testing.T) {
+			t.Parallel()
+
+			var builder strings.Builder
+
+			err := format.Write(&builder, test.format, results)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if builder.String() != test.want {
+				t.Fatalf("expected:\n%s\ngot:\n%s", test.want, builder.String())
+			}
+		})
+	}
+}
+
+func TestTSVRow(t *testing.T) {
+	t.Parallel()
+
+	columns := []db.Column{
+		{Name: "body", TypeOID: 25, TypeName: "text"},
+		{Name: "id", TypeOID: 23, TypeName: "int4"},
+	}
+
+	got := format.TSVRow(columns, []interface{}{"a\tb\nc\\d", nil})
+	if got != `a\tb\nc\\d`+"\t" {
+		t.Fatalf("unexpected row %q", got)
+	}
+}
+
+func TestRowJSON(t *testing.T) {
+	t.Parallel()
+
+	results := makeResults()
+
+	got := format.RowJSON(results.Columns, results.Rows[1])
+	if got != `{"name":"John","id":22,"email":"john@example.com"}` {
+		t.Fatalf("unexpected row %s", got)
+	}
+}
+
+func TestRowCount(t *testing.T) {
+	t.Parallel()
+
+	if format.RowCount(1) != "1 row" || format.RowCount(0) != "0 rows" {
+		t.Fatalf("unexpected row counts %q, %q", format.RowCount(1), format.RowCount(0))
+	}
+}
+
+func TestWriteInserts(t *testing.T) {
+	t.Parallel()
+
+	var builder strings.Builder
+
+	err := format.WriteInserts(&builder, makeResults(), "audit.Users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(builder.String(), `INSERT INTO audit."Users" (name, id, email)`) {
+		t.Fatalf("expected quoted, qualified table, got %s", builder.String())
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "users.sql")
+
+	err := format.WriteFile(path, makeResults(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(string(contents), "INSERT INTO users (name, id, email) VALUES") {
+		t.Fatalf("expected inserts into users, got %s", contents)
+	}
+
+	err = format.WriteFile(path, db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""}, false)
+	if !errors.Is(err, format.ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+
+	err = format.WriteFile(path, db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	contents, err = os.ReadFile(path)
+	if err != nil || len(contents) != 0 {
+		t.Fatalf("expected the file overwritten, got %q, %v", contents, err)
+	}
+
+	err = format.WriteFile(filepath.Join(t.TempDir(), "missing", "users.csv"), makeResults(), false)
+	if !errors.Is(err, format.ErrWrite) {
+		t.Fatalf("expected ErrWrite, got %v", err)
+	}
+}
+
+func TestForPath(t *testing.T) {
+	t.Parallel()
+
+	for path, want := range map[string]format.Format{
+		"results.csv":        format.FormatCSV,
+		"results.tsv":        format.FormatTSV,
+		"~/out/Results.JSON": format.FormatJSON,
+		"rows.jsonl":         format.FormatNDJSON,
+		"table.md":           format.FormatMarkdown,
+		"users.sql":          format.FormatSQL,
+		"results.txt":        format.FormatTable,
+	} {
+		got, err := format.ForPath(path)
+		if err != nil || got != want {
+			t.Errorf("ForPath(%q): expected %s, got %s, %v", path, want, got, err)
+		}
+	}
+
+	_, err := format.ForPath("results.xlsx")
+	if !errors.Is(err, format.ErrUnknownFormat) {
+		t.Fatalf("expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	got, err := format.Parse("NDJSON")
+	if err != nil || got != format.FormatNDJSON {
+		t.Fatalf("expected ndjson, got %q, %v", got, err)
+	}
+
+	_, err = format.Parse("xml")
+	if !errors.Is(err, format.ErrUnknownFormat) {
+		t.Fatalf("expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+
+	results := db.QueryResult{
+		Columns:    []db.Column{{Name: "body", TypeOID: 25, TypeName: "text"}},
+		Rows:       [][]interface{}{{strings.Repeat("x", 50)}},
+		CommandTag: "SELECT 1",
+	}
+
+	if !strings.Contains(format.Table(results, 0), strings.Repeat("x", 50)) {
+		t.Fatal("expected cells to be whole without a maximum width")
+	}
+
+	if !strings.Contains(format.Table(results, 10), strings.Repeat("x", 9)+"…") {
+		t.Fatal("expected cells to be truncated to the maximum width")
+	}
+}
+
+func TestTableWithOptions(t *testing.T) {
+	t.Parallel()
+
+	cursor := format.Cursor{
+		Row:    1,
+		Column: 2,
+		Style:  lipgloss.NewStyle().Transform(strings.ToUpper),
+	}
+
+	table, left, right := format.TableWithOptions(makeResults(), format.TableOptions{
+		MaxCellWidth: 0,
+		Cursor:       &cursor,
+		Null:         "NULL",
+	})
+
+	lines := strings.Split(table, "\n")
+	if lines[3] != " John      | 22 | JOHN@EXAMPLE.COM" {
+		t.Fatalf("expected the cursor's cell to be styled, got %q", lines[3])
+	}
+
+	if lines[2] != " Jane, Jr. |  1 | NULL" {
+		t.Fatalf("expected NULL to be shown, got %q", lines[2])
+	}
+
+	if left != 17 || right != 35 {
+		t.Fatalf("unexpected cell bounds %d to %d", left, right)
+	}
+}
@@ -0,0 +1,146 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/jshawl/dbq/internal/db"
+)
+
+// Cursor picks out the cell TableWithOptions renders in Style.
+type Cursor struct {
+	Row    int
+	Column int
+	Style  lipgloss.Style
+}
+
+// TableOptions adjust a table for display in the ui.
+type TableOptions struct {
+	// MaxCellWidth truncates wider cells; zero leaves them whole.
+	MaxCellWidth int
+	Cursor       *Cursor
+	// Null is shown for NULL, which is otherwise an empty cell as in psql.
+	Null string
+}
+
+// Table renders results aligned like psql. Cells wider than maxCellWidth
+// are truncated; zero leaves them whole.
+func Table(results db.QueryResult, maxCellWidth int) string {
+	table, _, _ := TableWithOptions(results, TableOptions{
+		MaxCellWidth: maxCellWidth,
+		Cursor:       nil,
+		Null:         "",
+	})
+
+	return table
+}
+
+// TableWithOptions renders like Table with the cursor's cell styled, and
+// also returns where the cell starts and ends on its line so it can be
+// scrolled into view.
+func TableWithOptions(results db.QueryResult, options TableOptions) (string, int, int) {
+	maxCellWidth := options.MaxCellWidth
+	cursor := options.Cursor
+	numColumns := len(results.Columns)
+	widths := make([]int, numColumns)
+	header := make([]string, numColumns)
+
+	for i, column := range results.Columns {
+		header[i] = truncateCell(column.Name, maxCellWidth)
+		widths[i] = lipgloss.Width(header[i])
+	}
+
+	cells := make([][]string, len(results.Rows))
+
+	for i, row := range results.Rows {
+		cells[i] = make([]string, numColumns)
+
+		for j, column := range results.Columns {
+			cell := db.FormatValue(row[j], column.TypeName)
+			if row[j] == nil {
+				cell = options.Null
+			}
+
+			cells[i][j] = truncateCell(cell, maxCellWidth)
+			widths[j] = max(widths[j], lipgloss.Width(cells[i][j]))
+		}
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString(tableLine(header, widths, nil, nil, nil))
+
+	separators := make([]string, numColumns)
+	for i, width := range widths {
+		separators[i] = strings.Repeat("-", width+2) //nolint:mnd // cell padding
+	}
+
+	builder.WriteString(strings.Join(separators, "+") + "\n")
+
+	left, right := 0, 0
+
+	for i, row := range results.Rows {
+		var rowCursor *Cursor
+		if cursor != nil && cursor.Row == i {
+			rowCursor = cursor
+		}
+
+		builder.WriteString(tableLine(cells[i], widths, results.Columns, row, rowCursor))
+	}
+
+	if cursor != nil && cursor.Column < numColumns {
+		for _, width := range widths[:cursor.Column] {
+			left += width + 3 //nolint:mnd // cell padding and separator
+		}
+
+		right = left + widths[cursor.Column] + 2 //nolint:mnd // cell padding
+	}
+
+	return builder.String(), left, right
+}
+
+func tableLine(
+	cells []string,
+	widths []int,
+	columns []db.Column,
+	values []interface{},
+	cursor *Cursor,
+) string {
+	padded := make([]string, len(cells))
+
+	for i, cell := range cells {
+		padding := strings.Repeat(" ", widths[i]-lipgloss.Width(cell))
+		if values != nil && isNumeric(values[i], columns[i].TypeName) {
+			padded[i] = " " + padding + cell + " "
+		} else {
+			padded[i] = " " + cell + padding + " "
+		}
+
+		if cursor != nil && cursor.Column == i {
+			padded[i] = cursor.Style.Render(padded[i])
+		}
+	}
+
+	return strings.TrimRight(strings.Join(padded, "|"), " ") + "\n"
+}
+
+func truncateCell(cell string, maxCellWidth int) string {
+	cell = strings.ReplaceAll(cell, "\n", "↵")
+	if maxCellWidth == 0 {
+		return cell
+	}
+
+	return ansi.Truncate(cell, maxCellWidth, "…")
+}
+
+func isNumeric(value interface{}, typeName string) bool {
+	switch value.(type) {
+	case nil:
+		return false
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	default:
+		return typeName == "numeric"
+	}
+}
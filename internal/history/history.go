@@ -3,8 +3,12 @@ package history
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"math"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	_ "github.com/mattn/go-sqlite3"
@@ -13,6 +17,28 @@ import (
 type Model struct {
 	cursor int64
 	db     *sql.DB
+	// profile names the connection queries are recorded against, and
+	// unless global is true the one whose queries up and down travel.
+	profile string
+	global  bool
+}
+
+// migrations bring the history database up to date, the one at index i
+// taking it from user_version i to i+1.
+//
+//nolint:gochecknoglobals
+var migrations = []string{
+	`create table if not exists history (
+		id integer not null primary key,
+		query text,
+		created_at datetime default current_timestamp
+	);`,
+	`alter table history add column profile text not null default '';
+	alter table history add column duration_ms integer;
+	alter table history add column row_count integer;
+	alter table history add column error text;
+	alter table history add column pinned integer not null default 0;`,
+	`alter table history add column params text;`,
 }
 
 func NewHistoryModel(path string) Model {
@@ -21,23 +47,78 @@ func NewHistoryModel(path string) Model {
 		log.Fatal(err)
 	}
 
-	sqlStmt := `
-		create table if not exists history (
-			id integer not null primary key,
-			query text,
-			created_at datetime default current_timestamp
-		);
-	`
-
-	_, err = database.ExecContext(context.Background(), sqlStmt)
+	err = migrate(database)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	return Model{
-		cursor: math.MaxInt32,
-		db:     database,
+		cursor:  math.MaxInt32,
+		db:      database,
+		profile: "",
+		global:  false,
+	}
+}
+
+// migrate runs the migrations the database has not had yet, each in its
+// own transaction.
+func migrate(database *sql.DB) error {
+	ctx := context.Background()
+
+	var version int
+
+	err := database.QueryRowContext(ctx, "pragma user_version").Scan(&version)
+	if err != nil {
+		return fmt.Errorf("failed to read history version: %w", err)
+	}
+
+	for ; version < len(migrations); version++ {
+		transaction, err := database.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to migrate history: %w", err)
+		}
+
+		_, err = transaction.ExecContext(ctx, migrations[version])
+		if err == nil {
+			_, err = transaction.ExecContext(
+				ctx,
+				fmt.Sprintf("pragma user_version = %d", version+1),
+			)
+		}
+
+		if err != nil {
+			_ = transaction.Rollback()
+
+			return fmt.Errorf("failed to migrate history to version %d: %w", version+1, err)
+		}
+
+		err = transaction.Commit()
+		if err != nil {
+			return fmt.Errorf("failed to migrate history: %w", err)
+		}
 	}
+
+	return nil
+}
+
+// SetProfile records the queries pushed from now on against the named
+// connection profile.
+func (model Model) SetProfile(name string) Model {
+	model.profile = name
+
+	return model
+}
+
+// SetGlobal makes up and down travel the queries of every profile, or
+// when global is false only the current one's.
+func (model Model) SetGlobal(global bool) Model {
+	model.global = global
+
+	return model
+}
+
+func (model Model) Global() bool {
+	return model.global
 }
 
 func (h Model) Cleanup() {
@@ -49,8 +130,28 @@ func (h Model) Cleanup() {
 	}()
 }
 
+// PushMsg records a query that ran, with how long it took, the rows it
+// returned, when it failed, why, and the values bound to its
+// placeholders.
 type PushMsg struct {
+	Query    string
+	Duration time.Duration
+	Rows     int
+	Err      error
+	Params   map[string]string
+}
+
+// RowsReadMsg completes the row count of the last run of Query, whose
+// rows were still being read when it was pushed.
+type RowsReadMsg struct {
 	Query string
+	Rows  int
+}
+
+// PinMsg pins every run of Query, or unpins them when Pinned is false.
+type PinMsg struct {
+	Query  string
+	Pinned bool
 }
 
 type pushedMsg struct {
@@ -70,9 +171,18 @@ func (model Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	//nolint:exhaustive
 	switch msg := msg.(type) {
 	case PushMsg:
-		query := msg.Query
-
-		return model, model.push(query)
+		return model, model.push(msg)
+	case RowsReadMsg:
+		return model, model.setRows(msg)
+	case PinMsg:
+		return model, func() tea.Msg {
+			err := model.SetPinned(msg.Query, msg.Pinned)
+			if err != nil {
+				log.Println(err)
+			}
+
+			return nil
+		}
 	case pushedMsg:
 		cursor := msg.id
 		model.cursor = cursor
@@ -109,6 +219,30 @@ func (model Model) Next() (int64, string) {
 }
 
 func (model Model) Push(query string) int64 {
+	return model.PushEntry(PushMsg{Query: query, Duration: 0, Rows: 0, Err: nil, Params: nil})
+}
+
+// PushEntry records msg's query against the model's profile and returns
+// its id. Running the profile's last query again bumps its entry rather
+// than adding another.
+func (model Model) PushEntry(msg PushMsg) int64 {
+	var errorMessage *string
+	if msg.Err != nil {
+		message := msg.Err.Error()
+		errorMessage = &message
+	}
+
+	var params *string
+	if len(msg.Params) > 0 {
+		data, err := json.Marshal(msg.Params)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		encoded := string(data)
+		params = &encoded
+	}
+
 	transaction, err := model.db.BeginTx(
 		context.Background(),
 		&sql.TxOptions{ReadOnly: false, Isolation: 0},
@@ -117,15 +251,63 @@ func (model Model) Push(query string) int64 {
 		log.Fatal("db begin err")
 	}
 
+	var (
+		lastID    int64
+		lastQuery string
+	)
+
+	err = transaction.QueryRowContext(
+		context.Background(),
+		"select id, query from history where profile = ? order by id desc limit 1",
+		model.profile,
+	).Scan(&lastID, &lastQuery)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Fatal(err)
+	}
+
+	if err == nil && lastQuery == msg.Query {
+		_, err = transaction.ExecContext(
+			context.Background(),
+			`update history
+			set created_at = current_timestamp,
+				duration_ms = ?, row_count = ?, error = ?, params = ?
+			where id = ?`,
+			msg.Duration.Milliseconds(),
+			msg.Rows,
+			errorMessage,
+			params,
+			lastID,
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = transaction.Commit()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return lastID
+	}
+
 	stmt, err := transaction.PrepareContext(
 		context.Background(),
-		"insert into history (query) values (?)",
+		`insert into history (query, profile, duration_ms, row_count, error, params)
+		values (?, ?, ?, ?, ?, ?)`,
 	)
 	if err != nil {
 		log.Fatal("prepare err")
 	}
 
-	result, _ := stmt.ExecContext(context.Background(), query)
+	result, _ := stmt.ExecContext(
+		context.Background(),
+		msg.Query,
+		model.profile,
+		msg.Duration.Milliseconds(),
+		msg.Rows,
+		errorMessage,
+		params,
+	)
 
 	err = transaction.Commit()
 	if err != nil {
@@ -147,20 +329,86 @@ func (model Model) Push(query string) int64 {
 	return lastInsertId
 }
 
+// LastParams returns the values last bound to query's placeholders,
+// preferring those given on the current profile, or nil when it has not
+// run with any.
+func (model Model) LastParams(query string) (map[string]string, error) {
+	var data []byte
+
+	err := model.db.QueryRowContext(
+		context.Background(),
+		`select params from history
+		where query = ? and params is not null
+		order by profile = ? desc, id desc
+		limit 1`,
+		query,
+		model.profile,
+	).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var params map[string]string
+
+	err = json.Unmarshal(data, &params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return params, nil
+}
+
+// SetPinned pins or unpins every run of query.
+func (model Model) SetPinned(query string, pinned bool) error {
+	_, err := model.db.ExecContext(
+		context.Background(),
+		"update history set pinned = ? where query = ?",
+		pinned,
+		query,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to pin history: %w", err)
+	}
+
+	return nil
+}
+
 func (model Model) dispatch(msg tea.Msg) tea.Cmd {
 	return func() tea.Msg {
 		return msg
 	}
 }
 
-func (model Model) push(query string) tea.Cmd {
+func (model Model) push(msg PushMsg) tea.Cmd {
 	return func() tea.Msg {
 		return pushedMsg{
-			id: model.Push(query),
+			id: model.PushEntry(msg),
 		}
 	}
 }
 
+func (model Model) setRows(msg RowsReadMsg) tea.Cmd {
+	return func() tea.Msg {
+		_, err := model.db.ExecContext(
+			context.Background(),
+			`update history set row_count = ?
+			where id = (select max(id) from history where query = ? and profile = ?)`,
+			msg.Rows,
+			msg.Query,
+			model.profile,
+		)
+		if err != nil {
+			log.Println(err)
+		}
+
+		return nil
+	}
+}
+
 func (model Model) travel(direction string) (int64, string) {
 	_, err := model.db.BeginTx(
 		context.Background(),
@@ -172,11 +420,13 @@ func (model Model) travel(direction string) (int64, string) {
 
 	var sql string
 	if direction == "next" {
-		sql = "select id, query from history where id > (?) order by id asc limit 1;"
+		sql = `select id, query from history
+			where id > (?) and (? or profile = ?) order by id asc limit 1;`
 	}
 
 	if direction == "previous" {
-		sql = "select id, query from history where id < (?) order by id desc limit 1;"
+		sql = `select id, query from history
+			where id < (?) and (? or profile = ?) order by id desc limit 1;`
 	}
 
 	stmt, _ := model.db.PrepareContext(
@@ -196,7 +446,12 @@ func (model Model) travel(direction string) (int64, string) {
 	//nolint:varnamelen
 	var id int64
 
-	err = stmt.QueryRowContext(context.Background(), model.cursor).Scan(&id, &query)
+	err = stmt.QueryRowContext(
+		context.Background(),
+		model.cursor,
+		model.global,
+		model.profile,
+	).Scan(&id, &query)
 	if err != nil {
 		cursor := model.cursor
 		if direction == "next" {
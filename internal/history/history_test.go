@@ -1,9 +1,12 @@
 package history_test
 
 import (
+	"database/sql"
+	"errors"
 	"math"
 	"os"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jshawl/dbq/internal/history"
@@ -55,6 +58,43 @@ func TestInit(t *testing.T) {
 		h1.Cleanup()
 		h2.Cleanup()
 	})
+
+	t.Run("migrates an existing db keeping its queries", func(t *testing.T) {
+		t.Parallel()
+
+		path := setupHistoryStore(t) + "/foo.db"
+
+		database, err := sql.Open("sqlite3", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = database.ExecContext(t.Context(), `
+			create table history (
+				id integer not null primary key,
+				query text,
+				created_at datetime default current_timestamp
+			);
+			insert into history (query) values ('select 1');`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		database.Close()
+
+		hist := history.NewHistoryModel(path)
+		defer hist.Cleanup()
+
+		entries, err := hist.Entries(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 1 || entries[0].Query != "select 1" ||
+			entries[0].Duration != nil || entries[0].Rows != nil || entries[0].Pinned {
+			t.Fatalf("expected the old query without metadata, got %+v", entries)
+		}
+	})
 }
 
 func setupHistoryModel(t *testing.T) history.Model {
@@ -148,6 +188,82 @@ func TestUpdate(t *testing.T) {
 	})
 }
 
+func TestPushEntry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("running the last query again bumps its entry", func(t *testing.T) {
+		t.Parallel()
+
+		hist := setupHistoryModel(t)
+		first := hist.Push("select 1")
+		hist.Push("select 2")
+
+		second := hist.Push("select 2")
+		if second != first+1 {
+			t.Fatalf("expected the entry to be bumped, got id %d", second)
+		}
+
+		if hist.Push("select 1") != second+1 {
+			t.Fatal("expected an earlier query to be added again")
+		}
+
+		// another profile's last query does not count
+		if hist.SetProfile("other").Push("select 1") != second+2 {
+			t.Fatal("expected another profile's query to be added")
+		}
+	})
+
+	t.Run("a bumped entry keeps the last run's metadata", func(t *testing.T) {
+		t.Parallel()
+
+		hist := setupHistoryModel(t)
+		hist.PushEntry(history.PushMsg{Query: "select 1", Duration: 0, Rows: 0, Err: errors.New("oops"), Params: nil})
+		hist.PushEntry(history.PushMsg{Query: "select 1", Duration: time.Second, Rows: 1, Err: nil, Params: nil})
+
+		entries, err := hist.Entries(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 1 || entries[0].Err != "" || *entries[0].Rows != 1 ||
+			*entries[0].Duration != time.Second {
+			t.Fatalf("expected one entry for the last run, got %+v", entries)
+		}
+	})
+}
+
+func TestLastParams(t *testing.T) {
+	t.Parallel()
+
+	push := func(hist history.Model, query string, params map[string]string) {
+		hist.PushEntry(history.PushMsg{Query: query, Duration: 0, Rows: 0, Err: nil, Params: params})
+	}
+
+	hist := setupHistoryModel(t).SetProfile("local")
+	query := "select * from users where id = $1"
+
+	params, err := hist.LastParams(query)
+	if err != nil || params != nil {
+		t.Fatalf("expected no params before the query ran, got %v, %v", params, err)
+	}
+
+	push(hist, query, map[string]string{"$1": "1"})
+	push(hist, "select 1", nil)
+	push(hist, query, map[string]string{"$1": "2"})
+	push(hist.SetProfile("staging"), query, map[string]string{"$1": "3"})
+	push(hist, "select 2", nil)
+
+	params, err = hist.LastParams(query)
+	if err != nil || params["$1"] != "2" {
+		t.Fatalf("expected the last values on this profile, got %v, %v", params, err)
+	}
+
+	params, err = hist.SetProfile("production").LastParams(query)
+	if err != nil || params["$1"] != "3" {
+		t.Fatalf("expected the last values on any profile, got %v, %v", params, err)
+	}
+}
+
 func TestPrevious(t *testing.T) {
 	t.Parallel()
 
@@ -183,6 +299,24 @@ func TestPrevious(t *testing.T) {
 		}
 	})
 
+	t.Run("other profiles' entries - skipped unless global", func(t *testing.T) {
+		t.Parallel()
+
+		hist := setupHistoryModel(t).SetProfile("local")
+		hist.Push("select * from users limit 1;")
+		hist.SetProfile("staging").Push("select * from users limit 2;")
+
+		_, query := hist.SetCursor(math.MaxInt32).Previous()
+		if query != "select * from users limit 1;" {
+			t.Fatalf("expected local's query, got %s", query)
+		}
+
+		_, query = hist.SetGlobal(true).SetCursor(math.MaxInt32).Previous()
+		if query != "select * from users limit 2;" {
+			t.Fatalf("expected staging's query, got %s", query)
+		}
+	})
+
 	t.Run("two entries - cursor decrements", func(t *testing.T) {
 		t.Parallel()
 
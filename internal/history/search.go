@@ -0,0 +1,249 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Entry is a query run in the past.
+type Entry struct {
+	ID        int64
+	Query     string
+	CreatedAt time.Time
+	Profile   string
+	// Duration and Rows are nil for queries recorded before they were.
+	Duration *time.Duration
+	Rows     *int64
+	// Err is why the query failed, or empty when it succeeded.
+	Err    string
+	Pinned bool
+}
+
+const (
+	// wordStartBonus is what matching the start of a word adds to a
+	// match's score.
+	wordStartBonus = 2
+	// recencyStep is how many places further back in the history an
+	// entry can be and still outrank one matching a point worse.
+	recencyStep = 10
+)
+
+// Entries returns up to limit runs of past queries, those of pinned
+// queries first and then most recent first. A query is pinned when any
+// of its runs is.
+func (model Model) Entries(limit int) ([]Entry, error) {
+	rows, err := model.db.QueryContext(context.Background(), `
+		select
+			id, query, created_at, profile, duration_ms, row_count, coalesce(error, ''),
+			(select max(pinned) from history as runs where runs.query = history.query) as pinned
+		from history
+		order by pinned desc, id desc
+		limit ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+
+	for rows.Next() {
+		var (
+			entry    Entry
+			duration sql.NullInt64
+			count    sql.NullInt64
+		)
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Query,
+			&entry.CreatedAt,
+			&entry.Profile,
+			&duration,
+			&count,
+			&entry.Err,
+			&entry.Pinned,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+
+		if duration.Valid {
+			took := time.Duration(duration.Int64) * time.Millisecond
+			entry.Duration = &took
+		}
+
+		if count.Valid {
+			entry.Rows = &count.Int64
+		}
+
+		entries = append(entries, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Search returns the entries whose queries contain pattern's characters
+// in order, ignoring case, pinned ones first and then best first. Matches
+// score higher for runs of consecutive characters and for starting
+// words, and lower the further back in entries, which are most recent
+// first, they are. Each query is returned once, as of its first entry
+// the pattern keeps.
+//
+// Words in pattern like @staging keep entries from profiles whose names
+// contain staging, and words like >30s those that took at least that
+// long.
+func Search(entries []Entry, pattern string) []Entry {
+	filter := parseFilter(pattern)
+
+	type match struct {
+		entry Entry
+		rank  int
+	}
+
+	var matches []match
+
+	seen := map[string]bool{}
+
+	for _, entry := range entries {
+		if !filter.keeps(entry) || seen[entry.Query] {
+			continue
+		}
+
+		place := len(seen)
+		seen[entry.Query] = true
+
+		score, ok := 0, true
+		if filter.text != "" {
+			score, ok = fuzzyScore(entry.Query, filter.text)
+		}
+
+		if ok {
+			matches = append(matches, match{entry: entry, rank: score*recencyStep - place})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a match, b match) int {
+		if a.entry.Pinned != b.entry.Pinned {
+			if a.entry.Pinned {
+				return -1
+			}
+
+			return 1
+		}
+
+		return b.rank - a.rank
+	})
+
+	found := make([]Entry, len(matches))
+	for i, match := range matches {
+		found[i] = match.entry
+	}
+
+	return found
+}
+
+// filter is a search pattern split into the text to match and the words
+// narrowing down which entries are searched.
+type filter struct {
+	text        string
+	profiles    []string
+	minDuration time.Duration
+}
+
+func parseFilter(pattern string) filter {
+	parsed := filter{text: "", profiles: nil, minDuration: 0}
+
+	var text []string
+
+	for word := range strings.FieldsSeq(pattern) {
+		if profile, ok := strings.CutPrefix(word, "@"); ok && profile != "" {
+			parsed.profiles = append(parsed.profiles, strings.ToLower(profile))
+
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(word, ">"); ok {
+			duration, err := time.ParseDuration(rest)
+			if err == nil {
+				parsed.minDuration = max(parsed.minDuration, duration)
+
+				continue
+			}
+		}
+
+		text = append(text, word)
+	}
+
+	parsed.text = strings.Join(text, " ")
+
+	return parsed
+}
+
+func (filter filter) keeps(entry Entry) bool {
+	for _, profile := range filter.profiles {
+		if !strings.Contains(strings.ToLower(entry.Profile), profile) {
+			return false
+		}
+	}
+
+	if filter.minDuration > 0 && (entry.Duration == nil || *entry.Duration < filter.minDuration) {
+		return false
+	}
+
+	return true
+}
+
+// fuzzyScore reports whether text contains pattern's characters in order,
+// and how well: each character scores the length of the run of matched
+// characters it ends, plus a bonus when it starts a word.
+func fuzzyScore(text string, pattern string) (int, bool) {
+	runes := []rune(strings.ToLower(text))
+	wanted := []rune(strings.ToLower(pattern))
+	best, found := 0, false
+
+	// trying from each place the first character appears finds the
+	// tightest match, where a single greedy pass might not
+	for start, char := range runes {
+		if char != wanted[0] {
+			continue
+		}
+
+		score, next, run := 0, 0, 0
+
+		for i := start; i < len(runes) && next < len(wanted); i++ {
+			if runes[i] != wanted[next] {
+				run = 0
+
+				continue
+			}
+
+			run++
+			next++
+			score += run
+
+			if i == 0 || !isWordRune(runes[i-1]) {
+				score += wordStartBonus
+			}
+		}
+
+		if next == len(wanted) {
+			best, found = max(best, score), true
+		}
+	}
+
+	return best, found
+}
+
+func isWordRune(char rune) bool {
+	return char == '_' || unicode.IsLetter(char) || unicode.IsDigit(char)
+}
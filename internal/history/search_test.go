@@ -0,0 +1,230 @@
+package history_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jshawl/dbq/internal/history"
+)
+
+func TestEntries(t *testing.T) {
+	t.Parallel()
+
+	hist := setupHistoryModel(t)
+	hist.Push("select 1")
+	hist.Push("select 2")
+	hist.Push("select 1")
+
+	entries, err := hist.Entries(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var queries []string
+	for _, entry := range entries {
+		queries = append(queries, entry.Query)
+	}
+
+	if !slices.Equal(queries, []string{"select 1", "select 2", "select 1"}) {
+		t.Fatalf("expected every run, most recent first, got %q", queries)
+	}
+
+	if entries[0].ID != 3 || entries[0].CreatedAt.IsZero() {
+		t.Fatalf("expected the last run's id and time, got %+v", entries[0])
+	}
+
+	entries, err = hist.Entries(1)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected the limit to apply, got %+v, %v", entries, err)
+	}
+}
+
+func TestEntries_Metadata(t *testing.T) {
+	t.Parallel()
+
+	hist := setupHistoryModel(t).SetProfile("staging")
+	hist.PushEntry(history.PushMsg{
+		Query:    "select * from users",
+		Duration: 1500 * time.Millisecond,
+		Rows:     0,
+		Err:      nil,
+		Params:   nil,
+	})
+	hist.PushEntry(history.PushMsg{
+		Query:    "select * from nope",
+		Duration: 3 * time.Millisecond,
+		Rows:     0,
+		Err:      errors.New(`relation "nope" does not exist`),
+		Params:   nil,
+	})
+
+	_, cmd := hist.Update(history.RowsReadMsg{Query: "select * from users", Rows: 250})
+	cmd()
+
+	_, cmd = hist.Update(history.PinMsg{Query: "select * from users", Pinned: true})
+	cmd()
+
+	entries, err := hist.Entries(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, nope := entries[0], entries[1]
+	if users.Query != "select * from users" || !users.Pinned {
+		t.Fatalf("expected the pinned query first, got %+v", entries)
+	}
+
+	if users.Profile != "staging" || *users.Duration != 1500*time.Millisecond ||
+		*users.Rows != 250 || users.Err != "" {
+		t.Fatalf("expected the run's metadata, got %+v", users)
+	}
+
+	if nope.Err != `relation "nope" does not exist` || nope.Pinned {
+		t.Fatalf("expected the error, got %+v", nope)
+	}
+
+	// a pin outlives running the query again
+	hist.Push("select * from users")
+
+	entries, err = hist.Entries(10)
+	if err != nil || !entries[0].Pinned {
+		t.Fatalf("expected the query to stay pinned, got %+v, %v", entries, err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	makeEntries := func(queries ...string) []history.Entry {
+		entries := make([]history.Entry, len(queries))
+		for i, query := range queries {
+			entries[i] = history.Entry{
+				ID:        int64(i),
+				Query:     query,
+				CreatedAt: time.Time{},
+				Profile:   "",
+				Duration:  nil,
+				Rows:      nil,
+				Err:       "",
+				Pinned:    false,
+			}
+		}
+
+		return entries
+	}
+
+	withMeta := func(entries []history.Entry, profiles []string, durations []time.Duration) []history.Entry {
+		for i := range entries {
+			entries[i].Profile = profiles[i]
+			entries[i].Duration = &durations[i]
+		}
+
+		return entries
+	}
+
+	pinned := makeEntries("select 2", "select 1")
+	pinned[1].Pinned = true
+
+	tests := []struct {
+		name    string
+		entries []history.Entry
+		pattern string
+		want    []string
+	}{
+		{
+			name:    "no pattern keeps recency order",
+			entries: makeEntries("select 2", "select 1"),
+			pattern: "",
+			want:    []string{"select 2", "select 1"},
+		},
+		{
+			name:    "characters in order, ignoring case",
+			entries: makeEntries("select * from users", "delete from posts", "SELECT 1"),
+			pattern: "sfu",
+			want:    []string{"select * from users"},
+		},
+		{
+			name:    "consecutive characters outrank scattered ones",
+			entries: makeEntries("select * from upserts", "select * from users"),
+			pattern: "users",
+			want:    []string{"select * from users", "select * from upserts"},
+		},
+		{
+			name:    "recency breaks ties",
+			entries: makeEntries("select * from users limit 2", "select * from users limit 1"),
+			pattern: "users",
+			want:    []string{"select * from users limit 2", "select * from users limit 1"},
+		},
+		{
+			name:    "word starts outrank the middle of words",
+			entries: makeEntries("select * from busers", "select * from users"),
+			pattern: "users",
+			want:    []string{"select * from users", "select * from busers"},
+		},
+		{
+			name:    "pinned entries come first",
+			entries: pinned,
+			pattern: "select",
+			want:    []string{"select 1", "select 2"},
+		},
+		{
+			name: "@ keeps entries from matching profiles",
+			entries: withMeta(
+				makeEntries("select 3", "select 2", "select 1"),
+				[]string{"local", "Staging", "production"},
+				[]time.Duration{0, 0, 0},
+			),
+			pattern: "@stag sel",
+			want:    []string{"select 2"},
+		},
+		{
+			name: "> keeps entries that took at least that long",
+			entries: withMeta(
+				makeEntries("select 3", "select 2", "select 1"),
+				[]string{"", "", ""},
+				[]time.Duration{time.Second, time.Minute, 30 * time.Second},
+			),
+			pattern: ">30s",
+			want:    []string{"select 2", "select 1"},
+		},
+		{
+			name:    "each query once, as of its latest run",
+			entries: makeEntries("select 1", "select 2", "select 1"),
+			pattern: "",
+			want:    []string{"select 1", "select 2"},
+		},
+		{
+			name: "filters runs before keeping each query once",
+			entries: withMeta(
+				makeEntries("select 1", "select 2", "select 1"),
+				[]string{"staging", "staging", "staging"},
+				[]time.Duration{time.Second, time.Second, time.Minute},
+			),
+			pattern: "@staging >30s",
+			want:    []string{"select 1"},
+		},
+		{
+			name:    "> without a duration is searched for",
+			entries: makeEntries("select 1 > x", "select 1"),
+			pattern: ">x",
+			want:    []string{"select 1 > x"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, entry := range history.Search(test.entries, test.pattern) {
+				got = append(got, entry.Query)
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
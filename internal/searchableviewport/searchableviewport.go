@@ -32,6 +32,15 @@ const (
 	SearchDirectionUp
 )
 
+const horizontalStep = 4
+
+func newViewport(width int, height int) viewport.Model {
+	vp := viewport.New(width, height)
+	vp.SetHorizontalStep(horizontalStep)
+
+	return vp
+}
+
 func NewSearchableViewportModel() Model {
 	return Model{
 		Height: 0,
@@ -43,7 +52,7 @@ func NewSearchableViewportModel() Model {
 		currentMatch:     -1,
 		matches:          nil,
 		ready:            false,
-		viewport:         viewport.New(0, 0),
+		viewport:         newViewport(0, 0),
 	}
 }
 
@@ -53,6 +62,47 @@ func (model *Model) SetContent(str string) {
 	model.viewport.SetContent(str)
 }
 
+// RefreshContent swaps in a re-rendering of the same content, e.g. with a
+// cursor moved, keeping the search and its highlighted matches.
+func (model *Model) RefreshContent(str string) {
+	model.content = str
+
+	if len(model.matches) > 0 && model.Search.Value != "" {
+		model.matches = search.Search(str, model.Search.Value)
+	}
+
+	if len(model.matches) == 0 {
+		model.viewport.SetContent(str)
+
+		return
+	}
+
+	model.currentMatch = min(model.currentMatch, len(model.matches)-1)
+	model.highlightContent = search.Highlight(str, model.matches, model.currentMatch)
+	model.viewport.SetContent(model.highlightContent)
+}
+
+// ShowLine scrolls as little as possible to bring line into view.
+func (model *Model) ShowLine(line int) {
+	switch {
+	case line < model.viewport.YOffset:
+		model.viewport.SetYOffset(line)
+	case line >= model.viewport.YOffset+model.viewport.Height:
+		model.viewport.SetYOffset(line - model.viewport.Height + 1)
+	}
+}
+
+// ShowColumns scrolls horizontally so the columns from left to right are
+// in view, favoring left when they are wider than the viewport.
+func (model *Model) ShowColumns(left int, right int) {
+	model.viewport.SetXOffset(min(left, max(right-model.viewport.Width, 0)))
+}
+
+// AtBottom reports whether the last line is in view.
+func (model Model) AtBottom() bool {
+	return model.viewport.AtBottom()
+}
+
 func cycle(current int, maximum int, direction SearchDirection) int {
 	if direction == SearchDirectionDown {
 		return (current + 1) % maximum
@@ -111,7 +161,7 @@ func (model Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case WindowSizeMsg:
 		height := msg.Height - footerHeight
 		if !model.ready {
-			model.viewport = viewport.New(msg.Width, height)
+			model.viewport = newViewport(msg.Width, height)
 			model.ready = true
 		} else {
 			model.viewport.Width = msg.Width
@@ -1,6 +1,7 @@
 package searchableviewport_test
 
 import (
+	"strconv"
 	"strings"
 	"testing"
 
@@ -154,6 +155,23 @@ func TestUpdate(t *testing.T) {
 			t.Fatal("expected highlighted view to update mark")
 		}
 	})
+
+	t.Run("right scrolls horizontally", func(t *testing.T) {
+		t.Parallel()
+
+		model := initializeViewport(t, searchableviewport.NewSearchableViewportModel())
+		model.SetContent("abcdefghijklmnop")
+
+		scrolledModel, _ := model.Update(tea.KeyMsg{
+			Alt:   false,
+			Paste: false,
+			Type:  tea.KeyRight,
+			Runes: nil,
+		})
+		if !strings.HasPrefix(scrolledModel.View(), "efgh") {
+			t.Fatalf("expected view to scroll right, got %s", scrolledModel.View())
+		}
+	})
 }
 
 func TestSetContent(t *testing.T) {
@@ -168,6 +186,67 @@ func TestSetContent(t *testing.T) {
 	}
 }
 
+func TestRefreshContent(t *testing.T) {
+	t.Parallel()
+
+	model := initializeViewport(t, searchableviewport.NewSearchableViewportModel())
+	model.SetContent("one\ntwo")
+	model.Search.Value = "two"
+	model, _ = model.Update(search.SearchMsg{Value: "two"})
+	model.RefreshContent("one\ntwo\nthree")
+
+	if model.Search.Value != "two" {
+		t.Fatalf("expected RefreshContent to keep the search, got %q", model.Search.Value)
+	}
+
+	if !strings.Contains(model.View(), "three") {
+		t.Fatalf("expected refreshed content, got %s", model.View())
+	}
+}
+
+func TestShowLine(t *testing.T) {
+	t.Parallel()
+
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = strconv.Itoa(i)
+	}
+
+	model := initializeViewport(t, searchableviewport.NewSearchableViewportModel())
+	model.SetContent(strings.Join(lines, "\n"))
+
+	model.ShowLine(12)
+
+	if strings.Fields(model.View())[0] != "4" {
+		t.Fatalf("expected line 12 at the bottom, got %s", model.View())
+	}
+
+	model.ShowLine(2)
+
+	if strings.Fields(model.View())[0] != "2" {
+		t.Fatalf("expected line 2 at the top, got %s", model.View())
+	}
+}
+
+func TestShowColumns(t *testing.T) {
+	t.Parallel()
+
+	model := initializeViewport(t, searchableviewport.NewSearchableViewportModel())
+	model.SetContent("abcdefghijklmnopqrstuvwxyz")
+
+	model.ShowColumns(12, 16)
+
+	if !strings.HasPrefix(model.View(), "ghijklmnop") {
+		t.Fatalf("expected columns 12 to 16 in view, got %s", model.View())
+	}
+
+	model.ShowColumns(0, 4)
+
+	if !strings.HasPrefix(model.View(), "abcd") {
+		t.Fatalf("expected the first columns in view, got %s", model.View())
+	}
+}
+
 func TestGetYOffset(t *testing.T) {
 	t.Parallel()
 
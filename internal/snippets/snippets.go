@@ -0,0 +1,125 @@
+package snippets
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Snippet is a named query kept for reuse.
+type Snippet struct {
+	Name  string
+	Query string
+}
+
+// Store keeps snippets as .sql files in a directory, ~/.dbq/snippets, so
+// they can be edited by hand and shared like any other file:
+//
+//	~/.dbq/snippets/active users.sql
+//	~/.dbq/snippets/orders by customer.sql
+type Store struct {
+	dir string
+}
+
+const (
+	extension = ".sql"
+	dirPerms  = 0o750
+	filePerms = 0o600
+)
+
+var (
+	ErrRead   = errors.New("failed to read snippets")
+	ErrWrite  = errors.New("failed to save snippet")
+	ErrName   = errors.New("invalid snippet name")
+	ErrExists = errors.New("snippet already exists")
+)
+
+func NewStore(dir string) Store {
+	return Store{dir: dir}
+}
+
+// Dir returns the snippets directory in dbq's config directory.
+func Dir(configDir string) string {
+	return filepath.Join(configDir, "snippets")
+}
+
+// List returns the snippets sorted by name. A missing directory holds
+// none.
+func (store Store) List() ([]Snippet, error) {
+	files, err := os.ReadDir(store.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRead, err)
+	}
+
+	var snippets []Snippet
+
+	for _, file := range files {
+		name, ok := strings.CutSuffix(file.Name(), extension)
+		if !ok || file.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(store.dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRead, err)
+		}
+
+		snippets = append(snippets, Snippet{Name: name, Query: string(data)})
+	}
+
+	slices.SortFunc(snippets, func(a Snippet, b Snippet) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	return snippets, nil
+}
+
+// Save keeps query under name. A snippet already named so is only
+// replaced when overwrite is true, and is otherwise left alone with
+// ErrExists.
+func (store Store) Save(name string, query string, overwrite bool) error {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: %q", ErrName, name)
+	}
+
+	err := os.MkdirAll(store.dir, dirPerms)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(filepath.Join(store.dir, name+extension), flags, filePerms)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w: %q", ErrExists, name)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	_, err = file.WriteString(strings.TrimSpace(query) + "\n")
+
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	return nil
+}
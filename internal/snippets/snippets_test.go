@@ -0,0 +1,103 @@
+package snippets_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jshawl/dbq/internal/snippets"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("a missing directory has no snippets", func(t *testing.T) {
+		t.Parallel()
+
+		list, err := snippets.NewStore(filepath.Join(t.TempDir(), "snippets")).List()
+		if err != nil || len(list) != 0 {
+			t.Fatalf("expected no snippets, got %+v, %v", list, err)
+		}
+	})
+
+	t.Run("saves and lists by name", func(t *testing.T) {
+		t.Parallel()
+
+		dir := snippets.Dir(t.TempDir())
+		store := snippets.NewStore(dir)
+
+		for _, snippet := range []snippets.Snippet{
+			{Name: "orders", Query: "select * from orders where user_id = :user_id"},
+			{Name: "Active users", Query: "select * from users where active\n"},
+		} {
+			err := store.Save(snippet.Name, snippet.Query, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a snippet"), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		list, err := store.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []snippets.Snippet{
+			{Name: "Active users", Query: "select * from users where active\n"},
+			{Name: "orders", Query: "select * from orders where user_id = :user_id\n"},
+		}
+		if !slices.Equal(list, want) {
+			t.Fatalf("expected %+v, got %+v", want, list)
+		}
+	})
+
+	t.Run("replaces a snippet only when asked to", func(t *testing.T) {
+		t.Parallel()
+
+		store := snippets.NewStore(t.TempDir())
+
+		err := store.Save("orders", "select * from orders", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = store.Save("orders", "select 1", false)
+		if !errors.Is(err, snippets.ErrExists) {
+			t.Fatalf("expected ErrExists, got %v", err)
+		}
+
+		list, err := store.List()
+		if err != nil || list[0].Query != "select * from orders\n" {
+			t.Fatalf("expected the snippet left alone, got %+v, %v", list, err)
+		}
+
+		err = store.Save("orders", "select 1", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		list, err = store.List()
+		if err != nil || list[0].Query != "select 1\n" {
+			t.Fatalf("expected the snippet replaced, got %+v, %v", list, err)
+		}
+	})
+
+	t.Run("rejects names that are not file names", func(t *testing.T) {
+		t.Parallel()
+
+		store := snippets.NewStore(t.TempDir())
+
+		for _, name := range []string{"", "  ", "../escape", `a\b`, ".hidden"} {
+			err := store.Save(name, "select 1", false)
+			if !errors.Is(err, snippets.ErrName) {
+				t.Fatalf("expected ErrName for %q, got %v", name, err)
+			}
+		}
+	})
+}
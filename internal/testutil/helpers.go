@@ -23,6 +23,43 @@ func AssertMsgType[T interface{}](t *testing.T, cmd tea.Cmd) T {
 	return typed
 }
 
+// FindMsgType runs cmd, descending into batches, and returns the first
+// msg of type T.
+func FindMsgType[T interface{}](t *testing.T, cmd tea.Cmd) T {
+	t.Helper()
+
+	if cmd == nil {
+		t.Fatalf("%T cmd is nil ", new(T))
+	}
+
+	msg, ok := findMsg[T](cmd())
+	if !ok {
+		t.Fatalf("Expected to find msg of type %T", *new(T))
+	}
+
+	return msg
+}
+
+func findMsg[T interface{}](msg tea.Msg) (T, bool) {
+	if typed, ok := msg.(T); ok {
+		return typed, true
+	}
+
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, cmd := range batch {
+			if cmd == nil {
+				continue
+			}
+
+			if typed, ok := findMsg[T](cmd()); ok {
+				return typed, true
+			}
+		}
+	}
+
+	return *new(T), false
+}
+
 func MakeKeyMsg(key tea.KeyType) tea.KeyMsg {
 	return tea.KeyMsg{
 		Alt:   false,
@@ -31,3 +68,21 @@ func MakeKeyMsg(key tea.KeyType) tea.KeyMsg {
 		Type:  key,
 	}
 }
+
+func MakeRuneKeyMsg(char rune) tea.KeyMsg {
+	return tea.KeyMsg{
+		Alt:   false,
+		Paste: false,
+		Runes: []rune{char},
+		Type:  tea.KeyRunes,
+	}
+}
+
+func MakeAltRuneKeyMsg(char rune) tea.KeyMsg {
+	return tea.KeyMsg{
+		Alt:   true,
+		Paste: false,
+		Runes: []rune{char},
+		Type:  tea.KeyRunes,
+	}
+}
@@ -26,6 +26,26 @@ func TestAssertMsgType(t *testing.T) {
 	}
 }
 
+func TestFindMsgType(t *testing.T) {
+	t.Parallel()
+
+	cmd := tea.Batch(
+		func() tea.Msg {
+			return nil
+		},
+		func() tea.Msg {
+			return Msg{
+				ok: true,
+			}
+		},
+	)
+
+	msg := testutil.FindMsgType[Msg](t, cmd)
+	if !msg.ok {
+		t.Fatal("expected msg.ok to be true")
+	}
+}
+
 func TestMakeKeyMsg(t *testing.T) {
 	t.Parallel()
 
@@ -35,3 +55,23 @@ func TestMakeKeyMsg(t *testing.T) {
 		t.Fatal("expected tea.Key")
 	}
 }
+
+func TestMakeRuneKeyMsg(t *testing.T) {
+	t.Parallel()
+
+	msg := testutil.MakeRuneKeyMsg('t')
+
+	if msg.String() != "t" {
+		t.Fatalf("expected t, got %s", msg.String())
+	}
+}
+
+func TestMakeAltRuneKeyMsg(t *testing.T) {
+	t.Parallel()
+
+	msg := testutil.MakeAltRuneKeyMsg('c')
+
+	if msg.String() != "alt+c" {
+		t.Fatalf("expected alt+c, got %s", msg.String())
+	}
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jshawl/dbq/internal/config"
+)
+
+// ConnectionPickerModel lists the config's profiles to switch between.
+type ConnectionPickerModel struct {
+	Profiles []config.Profile
+
+	cursor  int
+	visible bool
+}
+
+// ConnectMsg asks the ui to close the current connection and open the
+// profile's.
+type ConnectMsg struct {
+	Profile config.Profile
+}
+
+func NewConnectionPickerModel(profiles []config.Profile) ConnectionPickerModel {
+	return ConnectionPickerModel{
+		Profiles: profiles,
+
+		cursor:  0,
+		visible: false,
+	}
+}
+
+func (model ConnectionPickerModel) Update(msg tea.Msg) (ConnectionPickerModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !model.visible {
+		return model, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k", "ctrl+p":
+		model.cursor = max(model.cursor-1, 0)
+	case "down", "j", "ctrl+n":
+		model.cursor = min(model.cursor+1, len(model.Profiles)-1)
+	case "esc", "ctrl+g":
+		model.visible = false
+	case "enter":
+		if len(model.Profiles) == 0 {
+			return model, nil
+		}
+
+		model.visible = false
+
+		return model, dispatch(ConnectMsg{Profile: model.Profiles[model.cursor]})
+	}
+
+	return model, nil
+}
+
+// Show opens the picker with the cursor on the named profile.
+func (model ConnectionPickerModel) Show(current string) ConnectionPickerModel {
+	model.visible = true
+
+	for i, profile := range model.Profiles {
+		if profile.Name == current {
+			model.cursor = i
+		}
+	}
+
+	return model
+}
+
+func (model ConnectionPickerModel) Visible() bool {
+	return model.visible
+}
+
+func (model ConnectionPickerModel) View() string {
+	if len(model.Profiles) == 0 {
+		return "no profiles in ~/.dbq/config.yaml (esc to close)"
+	}
+
+	lines := []string{"switch connection (enter to connect, esc to close)", ""}
+	selected := lipgloss.NewStyle().Reverse(true)
+
+	for i, profile := range model.Profiles {
+		line := profileView(profile)
+		if profile.Schema != "" {
+			line += " schema=" + profile.Schema
+		}
+
+		if i == model.cursor {
+			line = selected.Render(line)
+		}
+
+		lines = append(lines, "  "+line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// profileView renders the profile's name in its color.
+func profileView(profile config.Profile) string {
+	text := "● " + profile.Name
+	if profile.ReadOnly {
+		text += " (read-only)"
+	}
+
+	if profile.Color == "" {
+		return text
+	}
+
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(profile.Color)).Render(text)
+}
@@ -0,0 +1,82 @@
+package ui_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jshawl/dbq/internal/config"
+	"github.com/jshawl/dbq/internal/testutil"
+	"github.com/jshawl/dbq/internal/ui"
+)
+
+func makeProfiles() []config.Profile {
+	return []config.Profile{
+		{Name: "local", DSN: "postgres://localhost/dbq", Schema: "", ReadOnly: false, Color: ""},
+		{Name: "replica", DSN: "postgres://replica/dbq", Schema: "", ReadOnly: true, Color: "#ff5f87"},
+	}
+}
+
+func TestConnectionPicker_Update(t *testing.T) {
+	t.Parallel()
+
+	t.Run("enter connects to the selected profile", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewConnectionPickerModel(makeProfiles()).Show("local")
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('j'))
+		model, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		msg := testutil.AssertMsgType[ui.ConnectMsg](t, cmd)
+		if msg.Profile.Name != "replica" {
+			t.Fatalf("expected replica, got %s", msg.Profile.Name)
+		}
+
+		if model.Visible() {
+			t.Fatal("expected picker to close")
+		}
+	})
+
+	t.Run("show selects the current profile", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewConnectionPickerModel(makeProfiles()).Show("replica")
+		_, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		msg := testutil.AssertMsgType[ui.ConnectMsg](t, cmd)
+		if msg.Profile.Name != "replica" {
+			t.Fatalf("expected replica, got %s", msg.Profile.Name)
+		}
+	})
+
+	t.Run("esc closes", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewConnectionPickerModel(makeProfiles()).Show("")
+		model, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEscape))
+
+		if cmd != nil || model.Visible() {
+			t.Fatal("expected picker to close without connecting")
+		}
+	})
+
+	t.Run("hidden", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewConnectionPickerModel(makeProfiles())
+
+		_, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+		if cmd != nil {
+			t.Fatal("expected hidden picker to ignore keys")
+		}
+	})
+}
+
+func TestConnectionPicker_View(t *testing.T) {
+	t.Parallel()
+
+	view := ui.NewConnectionPickerModel(makeProfiles()).Show("").View()
+	if !strings.Contains(view, "replica (read-only)") {
+		t.Fatalf("expected profiles in view, got %s", view)
+	}
+}
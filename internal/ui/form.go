@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FormModel asks for a few values, like a snippet's name or what to put
+// in a query's placeholders, and hands them to submit.
+type FormModel struct {
+	title   string
+	fields  []string
+	inputs  []textinput.Model
+	submit  func(values map[string]string) tea.Msg
+	focus   int
+	visible bool
+}
+
+func NewFormModel() FormModel {
+	return FormModel{
+		title:   "",
+		fields:  nil,
+		inputs:  nil,
+		submit:  nil,
+		focus:   0,
+		visible: false,
+	}
+}
+
+// Show opens the form with an input per field, filled in from values,
+// and dispatches what submit makes of them once the last is entered.
+func (model FormModel) Show(
+	title string,
+	fields []string,
+	values map[string]string,
+	submit func(values map[string]string) tea.Msg,
+) FormModel {
+	model.title = title
+	model.fields = fields
+	model.submit = submit
+	model.inputs = make([]textinput.Model, len(fields))
+	model.focus = 0
+	model.visible = true
+
+	for i, field := range fields {
+		input := textinput.New()
+		input.Prompt = field + " = "
+		input.Cursor.SetMode(1)
+		input.SetValue(values[field])
+		model.inputs[i] = input
+	}
+
+	if len(model.inputs) > 0 {
+		model.inputs[0].Focus()
+	}
+
+	return model
+}
+
+func (model FormModel) Visible() bool {
+	return model.visible
+}
+
+func (model FormModel) Update(msg tea.Msg) (FormModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !model.visible {
+		return model, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "ctrl+g":
+		model.visible = false
+
+		return model, nil
+	case "tab", "down":
+		return model.move(1), nil
+	case "shift+tab", "up":
+		return model.move(-1), nil
+	case "enter":
+		if model.focus < len(model.inputs)-1 {
+			return model.move(1), nil
+		}
+
+		model.visible = false
+
+		return model, dispatch(model.submit(model.values()))
+	}
+
+	if len(model.inputs) == 0 {
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+
+	// copied so the model this form came from keeps its own inputs
+	model.inputs = slices.Clone(model.inputs)
+	model.inputs[model.focus], cmd = model.inputs[model.focus].Update(keyMsg)
+
+	return model, cmd
+}
+
+func (model FormModel) move(fields int) FormModel {
+	if len(model.inputs) == 0 {
+		return model
+	}
+
+	model.inputs = slices.Clone(model.inputs)
+	model.inputs[model.focus].Blur()
+	model.focus = (model.focus + fields + len(model.inputs)) % len(model.inputs)
+	model.inputs[model.focus].Focus()
+
+	return model
+}
+
+func (model FormModel) values() map[string]string {
+	values := make(map[string]string, len(model.fields))
+	for i, field := range model.fields {
+		values[field] = model.inputs[i].Value()
+	}
+
+	return values
+}
+
+func (model FormModel) View() string {
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888"))
+	lines := []string{
+		model.title + " " + dim.Render("(tab to move, enter to submit, esc to cancel)"),
+		"",
+	}
+
+	for _, input := range model.inputs {
+		lines = append(lines, input.View())
+	}
+
+	return strings.Join(lines, "\n")
+}
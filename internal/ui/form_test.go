@@ -0,0 +1,70 @@
+package ui_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jshawl/dbq/internal/testutil"
+	"github.com/jshawl/dbq/internal/ui"
+)
+
+type formSubmittedMsg struct {
+	values map[string]string
+}
+
+func submitForm(values map[string]string) tea.Msg {
+	return formSubmittedMsg{values: values}
+}
+
+func TestForm_Update(t *testing.T) {
+	t.Parallel()
+
+	t.Run("enter moves through the fields and submits on the last", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewFormModel().Show(
+			"values",
+			[]string{"id", "name"},
+			map[string]string{"name": "ada"},
+			submitForm,
+		)
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('7'))
+		model, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		if cmd != nil || !model.Visible() {
+			t.Fatal("expected enter on the first field to move to the next")
+		}
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('!'))
+		model, cmd = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		msg := testutil.AssertMsgType[formSubmittedMsg](t, cmd)
+		if msg.values["id"] != "7" || msg.values["name"] != "ada!" {
+			t.Fatalf("expected the values typed after those given, got %v", msg.values)
+		}
+
+		if model.Visible() {
+			t.Fatal("expected the form to close")
+		}
+	})
+
+	t.Run("tab wraps around and esc cancels", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewFormModel().Show("values", []string{"a", "b"}, nil, submitForm)
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyTab))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyTab))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('x'))
+
+		if !strings.Contains(model.View(), "a = x") {
+			t.Fatalf("expected tab to wrap to the first field, got\n%s", model.View())
+		}
+
+		model, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEsc))
+		if cmd != nil || model.Visible() {
+			t.Fatal("expected esc to close without submitting")
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/jshawl/dbq/internal/format"
+	"github.com/jshawl/dbq/internal/history"
+)
+
+// historySearchLimit is how many past runs ctrl+r searches.
+const historySearchLimit = 1000
+
+// HistorySearchModel filters past queries to pick one for the query pane.
+type HistorySearchModel struct {
+	entries []history.Entry
+	matches []history.Entry
+	input   textinput.Model
+	err     error
+	cursor  int
+	visible bool
+	width   int
+	height  int
+}
+
+// HistoryEntriesMsg carries the past queries for the search to filter.
+type HistoryEntriesMsg struct {
+	Entries []history.Entry
+	Err     error
+}
+
+func NewHistorySearchModel() HistorySearchModel {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Cursor.SetMode(1)
+
+	return HistorySearchModel{
+		entries: nil,
+		matches: nil,
+		input:   input,
+		err:     nil,
+		cursor:  0,
+		visible: false,
+		width:   0,
+		height:  0,
+	}
+}
+
+func (model HistorySearchModel) Update(msg tea.Msg) (HistorySearchModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case HistoryEntriesMsg:
+		model.entries = msg.Entries
+		model.err = msg.Err
+
+		return model.filter(), nil
+	case tea.KeyMsg:
+		if !model.visible {
+			return model, nil
+		}
+
+		switch msg.String() {
+		case "up", "ctrl+p":
+			model.cursor = max(model.cursor-1, 0)
+
+			return model, nil
+		case "down", "ctrl+n", "ctrl+r":
+			model.cursor = max(min(model.cursor+1, len(model.matches)-1), 0)
+
+			return model, nil
+		case "esc", "ctrl+g":
+			model.visible = false
+
+			return model, nil
+		case "ctrl+s":
+			return model.pin()
+		case "enter":
+			if len(model.matches) == 0 {
+				return model, nil
+			}
+
+			model.visible = false
+
+			return model, dispatch(InsertQueryMsg{Value: model.matches[model.cursor].Query})
+		}
+
+		var cmd tea.Cmd
+
+		model.input, cmd = model.input.Update(msg)
+
+		return model.filter(), cmd
+	}
+
+	return model, nil
+}
+
+func (model HistorySearchModel) filter() HistorySearchModel {
+	model.matches = history.Search(model.entries, model.input.Value())
+	model.cursor = 0
+
+	return model
+}
+
+// pin pins the selected query, or unpins it, keeping it selected as the
+// pinned queries move to the top.
+func (model HistorySearchModel) pin() (HistorySearchModel, tea.Cmd) {
+	if len(model.matches) == 0 {
+		return model, nil
+	}
+
+	query := model.matches[model.cursor].Query
+	pinned := !model.matches[model.cursor].Pinned
+
+	model.entries = slices.Clone(model.entries)
+	for i := range model.entries {
+		if model.entries[i].Query == query {
+			model.entries[i].Pinned = pinned
+		}
+	}
+
+	model = model.filter()
+	model.cursor = max(slices.IndexFunc(model.matches, func(entry history.Entry) bool {
+		return entry.Query == query
+	}), 0)
+
+	return model, dispatch(history.PinMsg{Query: query, Pinned: pinned})
+}
+
+// Show opens the search with an empty filter, waiting on the entries.
+func (model HistorySearchModel) Show() HistorySearchModel {
+	model.visible = true
+	model.entries = nil
+	model.matches = nil
+	model.err = nil
+	model.cursor = 0
+	model.input.SetValue("")
+	model.input.Focus()
+
+	return model
+}
+
+func (model HistorySearchModel) Visible() bool {
+	return model.visible
+}
+
+func (model HistorySearchModel) SetSize(width int, height int) HistorySearchModel {
+	model.width = width
+	model.height = height
+
+	return model
+}
+
+func (model HistorySearchModel) View() string {
+	lines := []string{
+		"search history (enter to insert, ctrl+s to pin, @profile or >30s to filter, esc to close)",
+		model.input.View(),
+		"",
+	}
+
+	if model.err != nil {
+		return strings.Join(append(lines, model.err.Error()), "\n")
+	}
+
+	if len(model.matches) == 0 {
+		return strings.Join(append(lines, "no matching queries"), "\n")
+	}
+
+	// as many as fit between the heading and the selected query's error
+	shown := len(model.matches)
+	if model.height > 0 {
+		shown = max(model.height-len(lines)-1, 1)
+	}
+
+	start := max(0, min(model.cursor-shown/2, len(model.matches)-shown))
+	end := min(start+shown, len(model.matches))
+	selected := lipgloss.NewStyle().Reverse(true)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888"))
+	red := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+	profileWidth := 0
+	for _, entry := range model.matches[start:end] {
+		profileWidth = max(profileWidth, ansi.StringWidth(entry.Profile))
+	}
+
+	for i := start; i < end; i++ {
+		entry := model.matches[i]
+		meta := fmt.Sprintf(
+			"%s  %-*s  %7s  %-10s",
+			entry.CreatedAt.Local().Format("2006-01-02 15:04"),
+			profileWidth,
+			entry.Profile,
+			durationView(entry.Duration),
+			outcomeView(entry),
+		)
+
+		query := strings.Join(strings.Fields(entry.Query), " ")
+		if entry.Pinned {
+			query = "★ " + query
+		}
+
+		if model.width > 0 {
+			query = ansi.Truncate(query, max(model.width-ansi.StringWidth(meta)-4, 1), "…")
+		}
+
+		if i == model.cursor {
+			query = selected.Render(query)
+		}
+
+		if entry.Err != "" {
+			meta = red.Render(meta)
+		} else {
+			meta = dim.Render(meta)
+		}
+
+		lines = append(lines, "  "+meta+"  "+query)
+	}
+
+	if failed := model.matches[model.cursor].Err; failed != "" {
+		lines = append(lines, red.Render(failed))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// durationView renders how long a query took, or nothing when that was
+// not recorded.
+func durationView(duration *time.Duration) string {
+	switch {
+	case duration == nil:
+		return ""
+	case *duration < time.Second:
+		return fmt.Sprintf("%dms", duration.Milliseconds())
+	}
+
+	return fmt.Sprintf("%.1fs", duration.Seconds())
+}
+
+// outcomeView renders the rows a query returned or that it failed.
+func outcomeView(entry history.Entry) string {
+	switch {
+	case entry.Err != "":
+		return "error"
+	case entry.Rows == nil:
+		return ""
+	}
+
+	return format.RowCount(int(*entry.Rows))
+}
@@ -0,0 +1,155 @@
+package ui_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jshawl/dbq/internal/history"
+	"github.com/jshawl/dbq/internal/testutil"
+	"github.com/jshawl/dbq/internal/ui"
+)
+
+func makeHistoryEntries() []history.Entry {
+	createdAt := time.Date(2026, 10, 18, 9, 30, 0, 0, time.Local)
+	took := 15 * time.Millisecond
+	rows := int64(2)
+
+	return []history.Entry{
+		{
+			ID:        3,
+			Query:     "select *\nfrom posts",
+			CreatedAt: createdAt,
+			Profile:   "local",
+			Duration:  &took,
+			Rows:      &rows,
+			Err:       "",
+			Pinned:    false,
+		},
+		{
+			ID:        2,
+			Query:     "select * from users",
+			CreatedAt: createdAt,
+			Profile:   "staging",
+			Duration:  nil,
+			Rows:      nil,
+			Err:       "",
+			Pinned:    false,
+		},
+		{
+			ID:        1,
+			Query:     "delete from users where id = 1",
+			CreatedAt: createdAt,
+			Profile:   "staging",
+			Duration:  &took,
+			Rows:      nil,
+			Err:       `relation "users" does not exist`,
+			Pinned:    false,
+		},
+	}
+}
+
+func TestHistorySearch_Update(t *testing.T) {
+	t.Parallel()
+
+	t.Run("typing filters and enter inserts the selected query", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewHistorySearchModel().Show()
+		model, _ = model.Update(ui.HistoryEntriesMsg{Entries: makeHistoryEntries(), Err: nil})
+
+		for _, char := range "users" {
+			model, _ = model.Update(testutil.MakeRuneKeyMsg(char))
+		}
+
+		view := model.View()
+		if strings.Contains(view, "posts") || !strings.Contains(view, "delete from users") {
+			t.Fatalf("expected only queries matching users, got\n%s", view)
+		}
+
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyCtrlR))
+		model, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		msg := testutil.AssertMsgType[ui.InsertQueryMsg](t, cmd)
+		if msg.Value != "delete from users where id = 1" {
+			t.Fatalf("expected the second match, got %q", msg.Value)
+		}
+
+		if model.Visible() {
+			t.Fatal("expected the search to close")
+		}
+	})
+
+	t.Run("ctrl+s pins the selected query", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewHistorySearchModel().Show()
+		model, _ = model.Update(ui.HistoryEntriesMsg{Entries: makeHistoryEntries(), Err: nil})
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyDown))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyDown))
+		model, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyCtrlS))
+
+		msg := testutil.AssertMsgType[history.PinMsg](t, cmd)
+		if msg.Query != "delete from users where id = 1" || !msg.Pinned {
+			t.Fatalf("expected the selected query to be pinned, got %+v", msg)
+		}
+
+		model, cmd = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		inserted := testutil.AssertMsgType[ui.InsertQueryMsg](t, cmd)
+		if inserted.Value != msg.Query {
+			t.Fatalf("expected the pinned query to stay selected, got %q", inserted.Value)
+		}
+
+		lines := strings.Split(model.View(), "\n")
+		if !strings.Contains(lines[3], "★ delete from users") {
+			t.Fatalf("expected the pinned query first, got\n%s", model.View())
+		}
+	})
+
+	t.Run("esc closes", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewHistorySearchModel().Show()
+		model, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEsc))
+
+		if cmd != nil || model.Visible() {
+			t.Fatal("expected esc to close without inserting")
+		}
+	})
+}
+
+func TestHistorySearch_View(t *testing.T) {
+	t.Parallel()
+
+	model := ui.NewHistorySearchModel().Show()
+	model, _ = model.Update(ui.HistoryEntriesMsg{Entries: makeHistoryEntries(), Err: nil})
+
+	view := model.View()
+	if !strings.Contains(view, "2026-10-18 09:30  local       15ms  2 rows      select * from posts") {
+		t.Fatalf("expected queries on one line with their metadata, got\n%s", view)
+	}
+
+	if !strings.Contains(view, "staging     15ms  error       delete from users") {
+		t.Fatalf("expected the failed query marked, got\n%s", view)
+	}
+
+	model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyUp))
+	if strings.Contains(model.View(), "does not exist") {
+		t.Fatalf("expected no error for a query that ran, got\n%s", model.View())
+	}
+
+	for range 2 {
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyDown))
+	}
+
+	if !strings.HasSuffix(model.View(), `relation "users" does not exist`) {
+		t.Fatalf("expected the selected query's error, got\n%s", model.View())
+	}
+
+	model, _ = model.Update(testutil.MakeRuneKeyMsg('z'))
+	if !strings.Contains(model.View(), "no matching queries") {
+		t.Fatalf("expected no matches, got\n%s", model.View())
+	}
+}
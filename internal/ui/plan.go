@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jshawl/dbq/internal/db"
+)
+
+// slowestNodes is how many of a plan's nodes are highlighted as where
+// its time, or without ANALYZE its cost, goes.
+const slowestNodes = 3
+
+// planTree shows a plan as a tree whose nodes fold away their children.
+// Nodes are known by their path, the indexes of the children leading to
+// them, like "0.1".
+type planTree struct {
+	plan      db.Plan
+	collapsed map[string]bool
+	slowest   map[string]bool
+	// cursor is the index of the selected node among those shown.
+	cursor int
+}
+
+// planLine is a node shown in the tree.
+type planLine struct {
+	path  string
+	node  db.PlanNode
+	depth int
+}
+
+func newPlanTree(plan db.Plan) planTree {
+	tree := planTree{
+		plan:      plan,
+		collapsed: map[string]bool{},
+		slowest:   map[string]bool{},
+		cursor:    0,
+	}
+
+	if !plan.Costs {
+		return tree
+	}
+
+	lines := tree.lines(true)
+	slices.SortStableFunc(lines, func(a planLine, b planLine) int {
+		return cmp.Compare(tree.weight(b.node), tree.weight(a.node))
+	})
+
+	for _, line := range lines[:min(slowestNodes, len(lines))] {
+		if tree.weight(line.node) > 0 {
+			tree.slowest[line.path] = true
+		}
+	}
+
+	return tree
+}
+
+// weight is what makes a node slow: the time spent in it when the plan
+// was analyzed, and its estimated cost otherwise.
+func (tree planTree) weight(node db.PlanNode) float64 {
+	if tree.plan.Analyzed {
+		return node.SelfTime()
+	}
+
+	return node.SelfCost()
+}
+
+// lines lists the nodes depth first, leaving out the children of
+// collapsed nodes unless all is true.
+func (tree planTree) lines(all bool) []planLine {
+	var (
+		lines []planLine
+		walk  func(nodes []db.PlanNode, prefix string, depth int)
+	)
+
+	walk = func(nodes []db.PlanNode, prefix string, depth int) {
+		for i, node := range nodes {
+			path := prefix + fmt.Sprint(i)
+			lines = append(lines, planLine{path: path, node: node, depth: depth})
+
+			if all || !tree.collapsed[path] {
+				walk(node.Children, path+".", depth+1)
+			}
+		}
+	}
+
+	walk(tree.plan.Nodes, "", 0)
+
+	return lines
+}
+
+func (tree planTree) move(lines int) planTree {
+	tree.cursor = max(min(tree.cursor+lines, len(tree.lines(false))-1), 0)
+
+	return tree
+}
+
+// fold collapses or, when collapsed is false, expands the selected node.
+func (tree planTree) fold(collapsed bool) planTree {
+	lines := tree.lines(false)
+	if len(lines) == 0 || len(lines[tree.cursor].node.Children) == 0 {
+		return tree
+	}
+
+	// copied so the model this tree came from keeps its own
+	tree.collapsed = maps.Clone(tree.collapsed)
+	tree.collapsed[lines[tree.cursor].path] = collapsed
+
+	return tree
+}
+
+func (tree planTree) toggle() planTree {
+	lines := tree.lines(false)
+	if len(lines) == 0 {
+		return tree
+	}
+
+	return tree.fold(!tree.collapsed[lines[tree.cursor].path])
+}
+
+// view renders the tree and returns the line the cursor landed on.
+func (tree planTree) view() (string, int) {
+	var (
+		builder    strings.Builder
+		cursorLine int
+		lineCount  int
+	)
+
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888"))
+	slow := lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
+	selected := lipgloss.NewStyle().Reverse(true)
+
+	writeLine := func(line string) {
+		builder.WriteString(line + "\n")
+		lineCount++
+	}
+
+	if tree.plan.Analyzed {
+		writeLine(dim.Render(fmt.Sprintf(
+			"planning %.3f ms, execution %.3f ms",
+			tree.plan.PlanningTime,
+			tree.plan.ExecutionTime,
+		)))
+	}
+
+	lines := tree.lines(false)
+	if len(lines) == 0 {
+		writeLine("no plan")
+	}
+
+	for i, line := range lines {
+		indent := strings.Repeat(indentUnit, line.depth)
+
+		marker := "  "
+		if len(line.node.Children) > 0 && tree.collapsed[line.path] {
+			marker = "▸ "
+		} else if len(line.node.Children) > 0 {
+			marker = "▾ "
+		}
+
+		name := strings.TrimSpace(line.node.Operation + " " + line.node.Target)
+		if i == tree.cursor {
+			name = selected.Render(name)
+			cursorLine = lineCount
+		}
+
+		stats := tree.stats(line.node)
+		if tree.slowest[line.path] {
+			stats = slow.Render(stats)
+		} else {
+			stats = dim.Render(stats)
+		}
+
+		writeLine(strings.TrimRight(indent+marker+name+"  "+stats, " "))
+
+		for _, detail := range line.node.Details {
+			writeLine(indent + indentUnit + dim.Render(detail))
+		}
+	}
+
+	return builder.String(), cursorLine
+}
+
+// stats renders a node's estimates and, when analyzed, what was measured.
+func (tree planTree) stats(node db.PlanNode) string {
+	if !tree.plan.Costs {
+		return ""
+	}
+
+	stats := fmt.Sprintf(
+		"cost=%.2f..%.2f rows=%.0f",
+		node.StartupCost,
+		node.TotalCost,
+		node.PlanRows,
+	)
+
+	if tree.plan.Analyzed {
+		stats += fmt.Sprintf(
+			"  actual %.3f ms rows=%.0f loops=%.0f  self %.3f ms",
+			node.ActualTime,
+			node.ActualRows,
+			node.ActualLoops,
+			node.SelfTime(),
+		)
+	}
+
+	return stats
+}
@@ -1,34 +1,92 @@
 package ui
 
 import (
-	"github.com/charmbracelet/bubbles/textinput"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/jshawl/dbq/internal/completion"
+	"github.com/jshawl/dbq/internal/db"
 	"github.com/jshawl/dbq/internal/history"
 )
 
 type QueryPaneModel struct {
-	History   history.Model
-	TextInput textinput.Model
+	History  history.Model
+	TextArea textarea.Model
+	Catalog  db.Catalog
+
+	completions     []completion.Candidate
+	completionWord  string
+	completionIndex int
+	// destructive holds the statements waiting on confirmation before
+	// the query runs.
+	destructive []string
+	focused     bool
 
-	focused bool
+	// reading is the last query while its rows are still being read,
+	// rowsRead of them so far, to complete its history entry.
+	reading  string
+	rowsRead int
 }
 
+// QueryExecMsg asks to run Value, binding Params to its placeholders.
+// With placeholders and no Params, their values are asked for first.
 type QueryExecMsg struct {
-	Value string
+	Value  string
+	Params db.Params
+}
+
+// ExplainMsg asks for the plan of the statement in Value, running it
+// under EXPLAIN ANALYZE when Analyze is true. Params are bound like
+// QueryExecMsg's.
+type ExplainMsg struct {
+	Value   string
+	Analyze bool
+	Params  db.Params
 }
 
+const (
+	maxQueryPaneHeight = 10
+	maxCompletions     = 5
+	queryPaneWidth     = 80
+	indentUnit         = "  "
+)
+
 func NewQueryPaneModel(configPath string) QueryPaneModel {
-	input := textinput.New()
+	input := textarea.New()
 	input.Placeholder = "SELECT * FROM users LIMIT 1;"
+	input.ShowLineNumbers = false
+	input.CharLimit = 0
+	input.MaxHeight = 0
+	input.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	input.SetPromptFunc(len("> "), func(lineIdx int) string {
+		if lineIdx == 0 {
+			return "> "
+		}
+
+		return "  "
+	})
+	input.SetWidth(queryPaneWidth)
+	input.SetHeight(1)
 	input.Focus()
-	input.CharLimit = 256
-	input.Width = 80
 	input.Cursor.SetMode(1)
 
 	return QueryPaneModel{
-		TextInput: input,
-		History:   history.NewHistoryModel(configPath + "/history.sqlite3"),
-		focused:   true,
+		TextArea: input,
+		History:  history.NewHistoryModel(configPath + "/history.sqlite3"),
+		Catalog:  db.Catalog{Schemas: nil, Functions: nil},
+
+		completions:     nil,
+		completionWord:  "",
+		completionIndex: 0,
+		destructive:     nil,
+		focused:         true,
+
+		reading:  "",
+		rowsRead: 0,
 	}
 }
 
@@ -44,51 +102,299 @@ func (model QueryPaneModel) Update(msg tea.Msg) (QueryPaneModel, tea.Cmd) {
 			return model, nil
 		}
 
-		if msg.Type == tea.KeyEnter {
+		if len(model.destructive) > 0 {
+			return model.confirm(msg)
+		}
+
+		if len(model.completions) > 0 {
+			switch msg.String() {
+			case "ctrl+@", "down", "ctrl+n":
+				model.completionIndex = (model.completionIndex + 1) % len(model.completions)
+
+				return model, nil
+			case "up", "ctrl+p":
+				model.completionIndex = (model.completionIndex + len(model.completions) - 1) % len(model.completions)
+
+				return model, nil
+			case "enter":
+				return model.complete(model.completions[model.completionIndex]), nil
+			case "esc":
+				return model.closeCompletions(), nil
+			}
+
+			model = model.closeCompletions()
+		}
+
+		switch msg.String() {
+		case "ctrl+@":
+			return model.openCompletions(), nil
+		case "ctrl+j", "alt+enter":
+			model.destructive = db.Destructive(model.TextArea.Value())
+			if len(model.destructive) > 0 {
+				return model, nil
+			}
+
 			return model, dispatch(QueryExecMsg{
-				Value: model.TextInput.Value(),
+				Value:  model.TextArea.Value(),
+				Params: nil,
+			})
+		case "ctrl+x", "alt+x":
+			return model, dispatch(ExplainMsg{
+				Value:   model.TextArea.Value(),
+				Analyze: msg.String() == "alt+x",
+				Params:  nil,
 			})
+		case "enter":
+			model.TextArea.InsertString("\n" + model.indent())
+
+			return model.fitHeight(), nil
+		case "up":
+			if model.TextArea.Line() == 0 {
+				model.History, cmd = model.History.Update(msg)
+
+				return model, cmd
+			}
+		case "down":
+			if model.TextArea.Line() == model.TextArea.LineCount()-1 {
+				model.History, cmd = model.History.Update(msg)
+
+				return model, cmd
+			}
 		}
+
+		model.TextArea, cmd = model.TextArea.Update(msg)
+
+		return model.fitHeight(), cmd
 	case history.SetInputValueMsg:
-		model.TextInput.SetValue(msg.Value)
-		model.TextInput.SetCursor(len(msg.Value))
+		model.TextArea.SetValue(msg.Value)
+
+		return model.closeCompletions().fitHeight(), nil
+	case CatalogMsg:
+		if msg.Err == nil {
+			model.Catalog = msg.Catalog
+		}
 
 		return model, nil
 	case QueryResponseReceivedMsg:
-		if msg.Err != nil {
+		model.reading = ""
+		model.rowsRead = len(msg.Results.Rows)
+
+		if msg.rows != nil {
+			model.reading = msg.Query
+		}
+
+		return model, dispatch(history.PushMsg{
+			Query:    msg.Query,
+			Duration: msg.Duration,
+			Rows:     model.rowsRead,
+			Err:      msg.Err,
+			Params:   msg.Params,
+		})
+	case RowsMsg:
+		if model.reading == "" {
 			return model, nil
 		}
 
-		return model, dispatch(history.PushMsg{Query: msg.Query})
+		model.rowsRead += len(msg.Rows)
+
+		if !msg.Done && msg.Err == nil {
+			return model, nil
+		}
+
+		query := model.reading
+		model.reading = ""
+
+		return model, dispatch(history.RowsReadMsg{Query: query, Rows: model.rowsRead})
 	}
 
 	model.History, cmd = model.History.Update(msg)
 	cmds = append(cmds, cmd)
 
-	model.TextInput, cmd = model.TextInput.Update(msg)
+	model.TextArea, cmd = model.TextArea.Update(msg)
 	cmds = append(cmds, cmd)
 
 	return model, tea.Batch(cmds...)
 }
 
+// confirm runs the query when y answers the destructive statement
+// warning, and cancels it on any other key.
+func (model QueryPaneModel) confirm(msg tea.KeyMsg) (QueryPaneModel, tea.Cmd) {
+	model.destructive = nil
+
+	if msg.String() != "y" {
+		return model, nil
+	}
+
+	return model, dispatch(QueryExecMsg{
+		Value:  model.TextArea.Value(),
+		Params: nil,
+	})
+}
+
+func (model QueryPaneModel) confirmView() string {
+	statement := strings.Join(strings.Fields(model.destructive[0]), " ")
+	prompt := "is destructive, y to run it, any other key to cancel"
+
+	if len(model.destructive) > 1 {
+		prompt = fmt.Sprintf(
+			"and %d more are destructive, y to run them, any other key to cancel",
+			len(model.destructive)-1,
+		)
+	}
+
+	statement = ansi.Truncate(
+		statement,
+		max(model.TextArea.Width()-lipgloss.Width(prompt)-1, len("…")),
+		"…",
+	)
+
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("1")).
+		Render(statement + " " + prompt)
+}
+
+// indent returns the leading whitespace of the cursor's line, one level
+// deeper when the line opens a parenthesis.
+func (model QueryPaneModel) indent() string {
+	lines := strings.Split(model.TextArea.Value(), "\n")
+	line := lines[min(model.TextArea.Line(), len(lines)-1)]
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+	if strings.HasSuffix(strings.TrimRight(line, " \t"), "(") {
+		indent += indentUnit
+	}
+
+	return indent
+}
+
+// openCompletions offers the candidates for the word before the cursor,
+// completing it straight away when there is only one.
+func (model QueryPaneModel) openCompletions() QueryPaneModel {
+	word, candidates := completion.Complete(model.Catalog, model.TextArea.Value(), model.cursorOffset())
+
+	switch len(candidates) {
+	case 0:
+		return model
+	case 1:
+		model.completionWord = word
+
+		return model.complete(candidates[0])
+	}
+
+	model.completions = candidates
+	model.completionWord = word
+	model.completionIndex = 0
+
+	return model
+}
+
+// complete replaces the word before the cursor with the candidate. The
+// textarea only exposes editing through its key bindings, so the word is
+// removed with backspaces.
+func (model QueryPaneModel) complete(candidate completion.Candidate) QueryPaneModel {
+	for range []rune(model.completionWord) {
+		model.TextArea, _ = model.TextArea.Update(tea.KeyMsg{Type: tea.KeyBackspace, Runes: nil, Alt: false, Paste: false})
+	}
+
+	model.TextArea.InsertString(candidate.Insert)
+
+	return model.closeCompletions().fitHeight()
+}
+
+func (model QueryPaneModel) closeCompletions() QueryPaneModel {
+	model.completions = nil
+	model.completionWord = ""
+	model.completionIndex = 0
+
+	return model
+}
+
+// cursorOffset returns the cursor's byte offset into the textarea's value.
+func (model QueryPaneModel) cursorOffset() int {
+	lines := strings.Split(model.TextArea.Value(), "\n")
+	row := min(model.TextArea.Line(), len(lines)-1)
+	info := model.TextArea.LineInfo()
+	line := []rune(lines[row])
+	offset := len(string(line[:min(info.StartColumn+info.ColumnOffset, len(line))]))
+
+	for _, line := range lines[:row] {
+		offset += len(line) + 1
+	}
+
+	return offset
+}
+
+func (model QueryPaneModel) completionsView() string {
+	start := max(0, min(model.completionIndex-maxCompletions/2, len(model.completions)-maxCompletions))
+	end := min(start+maxCompletions, len(model.completions))
+	lines := make([]string, 0, end-start)
+	selected := lipgloss.NewStyle().Reverse(true)
+	kind := lipgloss.NewStyle().Foreground(lipgloss.Color("#888"))
+
+	for i := start; i < end; i++ {
+		line := "  " + model.completions[i].Label
+		if i == model.completionIndex {
+			line = selected.Render(line)
+		}
+
+		lines = append(lines, line+" "+kind.Render(string(model.completions[i].Kind)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Height returns the number of lines the pane takes up, including any open
+// completion menu or destructive statement warning.
+func (model QueryPaneModel) Height() int {
+	height := model.TextArea.Height() + min(len(model.completions), maxCompletions)
+	if len(model.destructive) > 0 {
+		height++
+	}
+
+	return height
+}
+
+func (model QueryPaneModel) fitHeight() QueryPaneModel {
+	model.TextArea.SetHeight(min(max(model.TextArea.LineCount(), 1), maxQueryPaneHeight))
+
+	return model
+}
+
+func (model QueryPaneModel) SetWidth(width int) QueryPaneModel {
+	model.TextArea.SetWidth(width)
+
+	return model
+}
+
 func (model QueryPaneModel) Focused() bool {
 	return model.focused
 }
 
 func (model QueryPaneModel) Focus() QueryPaneModel {
 	model.focused = true
-	model.TextInput.Focus()
+	model.TextArea.Focus()
 
 	return model
 }
 
 func (model QueryPaneModel) Blur() QueryPaneModel {
 	model.focused = false
-	model.TextInput.Blur()
+	model.destructive = nil
+	model.TextArea.Blur()
 
-	return model
+	return model.closeCompletions()
 }
 
 func (model QueryPaneModel) View() string {
-	return model.TextInput.View()
+	view := model.TextArea.View()
+
+	if len(model.completions) > 0 {
+		view += "\n" + model.completionsView()
+	}
+
+	if len(model.destructive) > 0 {
+		view += "\n" + model.confirmView()
+	}
+
+	return view
 }
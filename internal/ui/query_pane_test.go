@@ -1,6 +1,7 @@
 package ui_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -33,13 +34,13 @@ func TestQueryPane_Update(t *testing.T) {
 		}
 	})
 
-	t.Run("keys - enter", func(t *testing.T) {
+	t.Run("keys - ctrl+j", func(t *testing.T) {
 		t.Parallel()
 
 		model := setupQueryPaneModel(t)
-		want := "select * from posts limit 1;"
-		model.TextInput.SetValue(want)
-		_, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+		want := "select *\nfrom posts\nlimit 1;"
+		model.TextArea.SetValue(want)
+		_, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyCtrlJ))
 
 		queryMsg := testutil.AssertMsgType[ui.QueryExecMsg](t, cmd)
 
@@ -47,17 +48,219 @@ func TestQueryPane_Update(t *testing.T) {
 			t.Fatalf("expected QueryMsg.Value to be set, got %s", queryMsg.Value)
 		}
 	})
+
+	t.Run("keys - ctrl+x and alt+x explain", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupQueryPaneModel(t)
+		model.TextArea.SetValue("delete from posts")
+
+		_, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyCtrlX))
+		explainMsg := testutil.AssertMsgType[ui.ExplainMsg](t, cmd)
+
+		if explainMsg.Value != "delete from posts" || explainMsg.Analyze {
+			t.Fatalf("expected ctrl+x to explain without running, got %+v", explainMsg)
+		}
+
+		_, cmd = model.Update(testutil.MakeAltRuneKeyMsg('x'))
+		explainMsg = testutil.AssertMsgType[ui.ExplainMsg](t, cmd)
+
+		if !explainMsg.Analyze {
+			t.Fatalf("expected alt+x to explain analyze, got %+v", explainMsg)
+		}
+	})
+
+	t.Run("keys - ctrl+j with a destructive statement", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupQueryPaneModel(t)
+		want := "select 1;\ndelete\nfrom posts;"
+		model.TextArea.SetValue(want)
+		height := model.Height()
+		model, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyCtrlJ))
+
+		if cmd != nil {
+			t.Fatal("expected the query to wait for confirmation")
+		}
+
+		if !strings.Contains(model.View(), "delete from posts is destructive") {
+			t.Fatalf("expected a warning, got %s", model.View())
+		}
+
+		if model.Height() != height+1 {
+			t.Fatalf("expected the warning to take a line, got %d", model.Height())
+		}
+
+		cancelled, cmd := model.Update(testutil.MakeRuneKeyMsg('n'))
+		if cmd != nil || strings.Contains(cancelled.View(), "destructive") {
+			t.Fatal("expected any other key to cancel")
+		}
+
+		if cancelled.TextArea.Value() != want {
+			t.Fatalf("expected the key not to be typed, got %s", cancelled.TextArea.Value())
+		}
+
+		_, cmd = model.Update(testutil.MakeRuneKeyMsg('y'))
+
+		queryMsg := testutil.AssertMsgType[ui.QueryExecMsg](t, cmd)
+		if queryMsg.Value != want {
+			t.Fatalf("expected y to run the query, got %s", queryMsg.Value)
+		}
+	})
+
+	t.Run("keys - enter", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupQueryPaneModel(t)
+		model.TextArea.SetValue("select * from posts where id in (")
+		updatedModel, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		if cmd != nil {
+			t.Fatal("expected enter not to submit the query")
+		}
+
+		want := "select * from posts where id in (\n  "
+		if updatedModel.TextArea.Value() != want {
+			t.Fatalf("expected enter to insert an indented newline, got %q", updatedModel.TextArea.Value())
+		}
+
+		if updatedModel.TextArea.Height() != 2 {
+			t.Fatalf("expected query pane to grow, got height %d", updatedModel.TextArea.Height())
+		}
+	})
+
+	t.Run("keys - enter keeps indentation", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupQueryPaneModel(t)
+		model.TextArea.SetValue("select *\n  from posts")
+		updatedModel, _ := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		want := "select *\n  from posts\n  "
+		if updatedModel.TextArea.Value() != want {
+			t.Fatalf("expected enter to keep indentation, got %q", updatedModel.TextArea.Value())
+		}
+	})
+
+	t.Run("keys - up on first line travels history", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupQueryPaneModel(t)
+		model.TextArea.SetValue("select 1")
+		_, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyUp))
+
+		if cmd == nil {
+			t.Fatal("expected up to travel history")
+		}
+	})
+
+	t.Run("keys - up below first line moves cursor", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupQueryPaneModel(t)
+		model.TextArea.SetValue("select *\nfrom posts")
+		updatedModel, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyUp))
+
+		if cmd != nil {
+			t.Fatal("expected up not to travel history")
+		}
+
+		if updatedModel.TextArea.Line() != 0 {
+			t.Fatalf("expected cursor on first line, got %d", updatedModel.TextArea.Line())
+		}
+	})
+
+	t.Run("keys - down above last line moves cursor", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupQueryPaneModel(t)
+		model.TextArea.SetValue("select *\nfrom posts")
+		model.TextArea.CursorUp()
+		updatedModel, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyDown))
+
+		if cmd != nil {
+			t.Fatal("expected down not to travel history")
+		}
+
+		if updatedModel.TextArea.Line() != 1 {
+			t.Fatalf("expected cursor on last line, got %d", updatedModel.TextArea.Line())
+		}
+	})
+	t.Run("keys - ctrl+space completes a single candidate", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupQueryPaneModel(t)
+		model, _ = model.Update(ui.CatalogMsg{Catalog: makeCatalog(), Err: nil})
+		model.TextArea.SetValue("select em from users")
+		model.TextArea.SetCursor(len("select em"))
+
+		updatedModel, _ := model.Update(testutil.MakeKeyMsg(tea.KeyCtrlAt))
+
+		want := "select email from users"
+		if updatedModel.TextArea.Value() != want {
+			t.Fatalf("expected %q, got %q", want, updatedModel.TextArea.Value())
+		}
+	})
+
+	t.Run("keys - ctrl+space opens a menu of candidates", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupQueryPaneModel(t)
+		model, _ = model.Update(ui.CatalogMsg{Catalog: makeCatalog(), Err: nil})
+		model.TextArea.SetValue("select * from u")
+
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyCtrlAt))
+		if model.Height() != 1+5 {
+			t.Fatalf("expected completion menu below the query, got height %d", model.Height())
+		}
+
+		if !strings.Contains(model.View(), "users table") {
+			t.Fatalf("expected candidates in view, got %s", model.View())
+		}
+
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyDown))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		want := `select * from audit."User"`
+		if model.TextArea.Value() != want {
+			t.Fatalf("expected %q, got %q", want, model.TextArea.Value())
+		}
+
+		if model.Height() != 1 {
+			t.Fatalf("expected completion menu to close, got height %d", model.Height())
+		}
+	})
+
+	t.Run("keys - esc closes the completion menu", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupQueryPaneModel(t)
+		model, _ = model.Update(ui.CatalogMsg{Catalog: makeCatalog(), Err: nil})
+		model.TextArea.SetValue("select * from u")
+
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyCtrlAt))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEscape))
+
+		if model.Height() != 1 || model.TextArea.Value() != "select * from u" {
+			t.Fatalf("expected menu to close without completing, got %q", model.TextArea.Value())
+		}
+	})
+
 	t.Run("history.SetInputValueMsg", func(t *testing.T) {
 		t.Parallel()
 
 		model := setupQueryPaneModel(t)
-		_, cmd := model.Update(history.SetInputValueMsg{
-			Value: "select * from posts limit 1;",
+		updatedModel, cmd := model.Update(history.SetInputValueMsg{
+			Value: "select *\nfrom posts\nlimit 1;",
 		})
 
 		if cmd != nil {
 			t.Fatal("expected cmd to be nil")
 		}
+
+		if updatedModel.TextArea.Height() != 3 {
+			t.Fatalf("expected query pane to fit the query, got height %d", updatedModel.TextArea.Height())
+		}
 	})
 
 	t.Run("QueryResponseReceivedMsg - Err", func(t *testing.T) {
@@ -66,15 +269,18 @@ func TestQueryPane_Update(t *testing.T) {
 		model := setupQueryPaneModel(t)
 		_, cmd := model.Update(ui.QueryResponseReceivedMsg{
 			QueryMsg: ui.QueryMsg{
-				Duration: 0,
-				Err:      errSQL,
-				Query:    "not sql",
-				Results:  db.QueryResult{},
+				Duration:   0,
+				Err:        errSQL,
+				Query:      "not sql",
+				Results:    db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
+				Statements: nil,
+				TxStatus:   db.TxIdle,
 			},
 		})
 
-		if cmd != nil {
-			t.Fatal("expected cmd to be nil")
+		msg := testutil.AssertMsgType[history.PushMsg](t, cmd)
+		if msg.Query != "not sql" || !errors.Is(msg.Err, errSQL) {
+			t.Fatalf("expected the failure to be recorded, got %+v", msg)
 		}
 	})
 
@@ -84,18 +290,29 @@ func TestQueryPane_Update(t *testing.T) {
 		model := setupQueryPaneModel(t)
 		_, cmd := model.Update(ui.QueryResponseReceivedMsg{
 			QueryMsg: ui.QueryMsg{
-				Duration: time.Millisecond * 2345,
-				Err:      nil,
-				Results:  makeResults(456),
-				Query:    "select * from foo;",
+				Duration:   time.Millisecond * 2345,
+				Err:        nil,
+				Results:    makeResults(456),
+				Query:      "select * from foo where id = $1;",
+				Params:     db.Params{"$1": "7"},
+				Statements: nil,
+				TxStatus:   db.TxIdle,
 			},
 		})
 
 		msg := testutil.AssertMsgType[history.PushMsg](t, cmd)
 
-		if msg.Query != "select * from foo;" {
+		if msg.Query != "select * from foo where id = $1;" {
 			t.Fatal("expected history push msg")
 		}
+
+		if msg.Duration != time.Millisecond*2345 || msg.Rows != 1 || msg.Err != nil {
+			t.Fatalf("expected the duration and row count to be recorded, got %+v", msg)
+		}
+
+		if msg.Params["$1"] != "7" {
+			t.Fatalf("expected the bound values to be recorded, got %+v", msg.Params)
+		}
 	})
 }
 
@@ -1,13 +1,22 @@
 package ui
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"sort"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/jshawl/dbq/internal/db"
+	"github.com/jshawl/dbq/internal/format"
 	"github.com/jshawl/dbq/internal/searchableviewport"
 )
 
@@ -16,34 +25,335 @@ type ResultsPaneModel struct {
 	Results            db.QueryResult
 	Err                error
 	SearchableViewport searchableviewport.Model
+	ViewMode           ResultsViewMode
+	Statements         []db.DBQueryResult
 
-	focused bool
+	statement    int
+	cursorRow    int
+	cursorColumn int
+	focused      bool
+	running      bool
+	startedAt    time.Time
+	elapsed      time.Duration
+	exportInput  textinput.Model
+	notice       string
+	// overwrite is the export path waiting on confirmation to replace the
+	// file already there.
+	overwrite string
+
+	// pending is true while the last statement has more rows to read,
+	// which are asked for as the cursor or scrolling nears the end.
+	pending        bool
+	fetchRequested bool
+	truncated      bool
+
+	// detail, when open, shows the cursor's row in full in place of the
+	// list, which keeps its scroll position for when detail is closed.
+	detail     *searchableviewport.Model
+	windowSize searchableviewport.WindowSizeMsg
+
+	// edits are staged changes to the shown statement's cells, written
+	// back as UPDATEs when committed from the preview.
+	edits     []cellEdit
+	editable  *db.Editable
+	editInput textinput.Model
+	catalog   db.Catalog
+	// preview is true while detail shows the staged edits' UPDATEs.
+	preview bool
+	// plan, when open, is shown in detail as a tree.
+	plan *planTree
+}
+
+// cellEdit is a staged new value for a cell. A nil value is NULL.
+type cellEdit struct {
+	row    int
+	column int
+	value  *string
+}
+
+// CommitEditsMsg asks the ui to run the staged edits' UPDATEs in a
+// transaction.
+type CommitEditsMsg struct {
+	Statements []string
+
+	edits []cellEdit
+}
+
+// EditsCommittedMsg reports a finished CommitEditsMsg.
+type EditsCommittedMsg struct {
+	Err error
+
+	edits []cellEdit
+}
+
+// CopyMsg asks the ui to put Text on the clipboard.
+type CopyMsg struct {
+	Text        string
+	Description string
 }
 
+// CopiedMsg reports a finished CopyMsg.
+type CopiedMsg struct {
+	Description string
+	Err         error
+}
+
+// ExportMsg reports a finished export started with x. Partial is true
+// when the result had more rows than were read.
+type ExportMsg struct {
+	Path    string
+	Rows    int
+	Partial bool
+	Err     error
+}
+
+const (
+	maxCellWidth      = 40
+	defaultExportPath = "results.csv"
+	detailIndent      = 2
+	// fetchAhead is how close to the last row the cursor gets before the
+	// next batch is asked for.
+	fetchAhead = 50
+)
+
+type queryTickMsg struct {
+	time time.Time
+}
+
+const queryTickInterval = 100 * time.Millisecond
+
+type ResultsViewMode int
+
+const (
+	ResultsViewModeRecord ResultsViewMode = iota
+	ResultsViewModeTable
+)
+
 func NewResultsPaneModel() ResultsPaneModel {
+	exportInput := textinput.New()
+	exportInput.Prompt = "export to: "
+	exportInput.Cursor.SetMode(1)
+
+	editInput := textinput.New()
+	editInput.Cursor.SetMode(1)
+
 	return ResultsPaneModel{
 		Duration:           0,
-		Results:            db.QueryResult{},
+		Results:            db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
 		Err:                nil,
 		SearchableViewport: searchableviewport.NewSearchableViewportModel(),
+		ViewMode:           ResultsViewModeRecord,
+		Statements:         nil,
+
+		statement:    0,
+		cursorRow:    0,
+		cursorColumn: 0,
+		focused:      false,
+		running:      false,
+		startedAt:    time.Time{},
+		elapsed:      0,
+		exportInput:  exportInput,
+		notice:       "",
+		overwrite:    "",
 
-		focused: false,
+		pending:        false,
+		fetchRequested: false,
+		truncated:      false,
+
+		detail:     nil,
+		windowSize: searchableviewport.WindowSizeMsg{Height: 0, Width: 0},
+
+		edits:     nil,
+		editable:  nil,
+		editInput: editInput,
+		catalog:   db.Catalog{Schemas: nil, Functions: nil},
+		preview:   false,
+		plan:      nil,
 	}
 }
 
 func (model ResultsPaneModel) Update(msg tea.Msg) (ResultsPaneModel, tea.Cmd) {
+	model, cmd := model.update(msg)
+
+	if !model.wantsRows() {
+		return model, cmd
+	}
+
+	model.fetchRequested = true
+
+	return model, tea.Batch(cmd, dispatch(FetchRowsMsg{}))
+}
+
+func (model ResultsPaneModel) update(msg tea.Msg) (ResultsPaneModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if !model.focused {
 			return model, nil
 		}
+
+		model.notice = ""
+
+		if model.overwrite != "" {
+			return model.confirmOverwrite(msg)
+		}
+
+		if model.exportInput.Focused() {
+			return model.updateExport(msg)
+		}
+
+		if model.editInput.Focused() {
+			return model.updateEdit(msg)
+		}
+
+		if model.detail != nil {
+			return model.updateDetail(msg)
+		}
+
+		if !model.SearchableViewport.Search.Focused() {
+			switch msg.String() {
+			case "enter":
+				return model.openDetail(), nil
+			case "j":
+				return model.moveCursor(1, 0), nil
+			case "k":
+				return model.moveCursor(-1, 0), nil
+			case "l":
+				return model.moveCursor(0, 1), nil
+			case "h":
+				return model.moveCursor(0, -1), nil
+			case "y", "Y", "J", "A":
+				return model, model.copy(msg.String())
+			case "x":
+				return model.startExport(), nil
+			case "e":
+				return model.startEdit(), nil
+			case "p":
+				return model.openPreview(), nil
+			case "D":
+				return model.discardEdits(), nil
+			case "t":
+				return model.toggleViewMode(), nil
+			case "[":
+				return model.showStatement(model.statement - 1), nil
+			case "]":
+				return model.showStatement(model.statement + 1), nil
+			}
+		}
+	case QueryStartedMsg:
+		model.running = true
+		model.startedAt = msg.StartedAt
+		model.elapsed = 0
+
+		return model, queryTick()
+	case queryTickMsg:
+		if !model.running {
+			return model, nil
+		}
+
+		model.elapsed = msg.time.Sub(model.startedAt)
+
+		return model, queryTick()
+	case SnippetSavedMsg:
+		if msg.Err != nil {
+			model.notice = msg.Err.Error()
+		} else {
+			model.notice = "saved snippet " + msg.Name
+		}
+
+		return model, nil
+	case CopiedMsg:
+		if msg.Err != nil {
+			model.notice = msg.Err.Error()
+		} else {
+			model.notice = "copied " + msg.Description
+		}
+
+		return model, nil
+	case EditsCommittedMsg:
+		model.running = false
+
+		if msg.Err != nil {
+			model.notice = msg.Err.Error()
+
+			return model, nil
+		}
+
+		return model.commitEdits(msg.edits), nil
+	case ExplainedMsg:
+		model.running = false
+
+		if msg.Err != nil {
+			model.notice = msg.Err.Error()
+
+			return model, nil
+		}
+
+		return model.openPlan(msg.Plan), nil
+	case TxEndedMsg:
+		model.running = false
+
+		if msg.Err != nil {
+			model.notice = msg.Err.Error()
+		} else {
+			model.notice = msg.CommandTag
+		}
+
+		return model, nil
+	case CatalogMsg:
+		if msg.Err == nil {
+			model.catalog = msg.Catalog
+		}
+
+		return model, nil
+	case ExportMsg:
+		if errors.Is(msg.Err, format.ErrExists) {
+			model.overwrite = msg.Path
+
+			return model, nil
+		}
+
+		if msg.Err != nil {
+			model.notice = msg.Err.Error()
+		} else {
+			model.notice = fmt.Sprintf("exported %s to %s", rowCount(msg.Rows, msg.Partial), msg.Path)
+		}
+
+		return model, nil
+	case searchableviewport.WindowSizeMsg:
+		model.windowSize = msg
+
+		if model.detail != nil {
+			detail, _ := model.detail.Update(msg)
+			detail.SetContent(model.detailContent())
+			model.detail = &detail
+		}
 	case QueryResponseReceivedMsg:
+		model.running = false
+		model.notice = ""
+		model.detail = nil
+		model.preview = false
+		model.plan = nil
+		model.edits = nil
+		model.Statements = msg.Statements
+		model.pending = msg.rows != nil
+		model.fetchRequested = false
+
+		if len(msg.Statements) > 1 {
+			return model.showStatement(len(msg.Statements) - 1), nil
+		}
+
+		model.statement = 0
+		model.cursorRow = 0
+		model.cursorColumn = 0
 		model.Duration = msg.Duration
 		model.Err = msg.Err
 		model.Results = msg.Results
+		model.truncated = len(msg.Statements) == 1 && msg.Statements[0].Truncated
 		model.SearchableViewport.SetContent(model.ResultsView())
 
 		return model, nil
+	case RowsMsg:
+		return model.appendRows(msg), nil
 	}
 
 	var (
@@ -51,12 +361,604 @@ func (model ResultsPaneModel) Update(msg tea.Msg) (ResultsPaneModel, tea.Cmd) {
 		cmds []tea.Cmd
 	)
 
+	// the detail's search messages are its own, but both need resizing
+	if model.detail != nil {
+		if _, ok := msg.(searchableviewport.WindowSizeMsg); !ok {
+			detail, cmd := model.detail.Update(msg)
+			model.detail = &detail
+
+			return model, cmd
+		}
+	}
+
 	model.SearchableViewport, cmd = model.SearchableViewport.Update(msg)
 	cmds = append(cmds, cmd)
 
 	return model, tea.Batch(cmds...)
 }
 
+func (model ResultsPaneModel) openDetail() ResultsPaneModel {
+	if len(model.Results.Rows) == 0 || model.Err != nil {
+		return model
+	}
+
+	model.preview = false
+	model.plan = nil
+	detail, _ := searchableviewport.NewSearchableViewportModel().Update(model.windowSize)
+	detail.SetContent(model.detailContent())
+	model.detail = &detail
+
+	return model
+}
+
+func (model ResultsPaneModel) updateDetail(msg tea.KeyMsg) (ResultsPaneModel, tea.Cmd) {
+	searching := model.detail.Search.Focused() || model.detail.Search.Value != ""
+	if msg.String() == "esc" && !searching {
+		model.detail = nil
+		model.preview = false
+		model.plan = nil
+
+		return model, nil
+	}
+
+	if model.plan != nil && !model.detail.Search.Focused() {
+		switch msg.String() {
+		case "j":
+			return model.updatePlan(model.plan.move(1)), nil
+		case "k":
+			return model.updatePlan(model.plan.move(-1)), nil
+		case "enter", " ":
+			return model.updatePlan(model.plan.toggle()), nil
+		case "h":
+			return model.updatePlan(model.plan.fold(true)), nil
+		case "l":
+			return model.updatePlan(model.plan.fold(false)), nil
+		}
+	}
+
+	if model.preview && !model.detail.Search.Focused() {
+		switch msg.String() {
+		case "enter":
+			return model, dispatch(CommitEditsMsg{Statements: model.updates(), edits: model.edits})
+		case "D":
+			return model.discardEdits(), nil
+		}
+	}
+
+	detail, cmd := model.detail.Update(msg)
+	model.detail = &detail
+
+	return model, cmd
+}
+
+// openPlan shows plan in detail as a tree.
+func (model ResultsPaneModel) openPlan(plan db.Plan) ResultsPaneModel {
+	tree := newPlanTree(plan)
+	model.plan = &tree
+	model.preview = false
+	detail, _ := searchableviewport.NewSearchableViewportModel().Update(model.windowSize)
+	detail.SetContent(model.detailContent())
+	model.detail = &detail
+
+	return model
+}
+
+// updatePlan shows tree, moved or folded, keeping its cursor in view.
+func (model ResultsPaneModel) updatePlan(tree planTree) ResultsPaneModel {
+	model.plan = &tree
+	content, cursorLine := tree.view()
+
+	detail := *model.detail
+	detail.RefreshContent(content)
+	detail.ShowLine(cursorLine)
+	model.detail = &detail
+
+	return model
+}
+
+// DetailOpen reports whether the cursor's row is shown in full.
+func (model ResultsPaneModel) DetailOpen() bool {
+	return model.detail != nil
+}
+
+func (model ResultsPaneModel) detailContent() string {
+	if model.plan != nil {
+		content, _ := model.plan.view()
+
+		return content
+	}
+
+	if model.preview {
+		return model.previewView()
+	}
+
+	return model.detailView()
+}
+
+// detailView renders every field of the cursor's row with values whole,
+// JSON indented and long lines wrapped to the pane.
+func (model ResultsPaneModel) detailView() string {
+	var builder strings.Builder
+
+	nameStyle := lipgloss.NewStyle().Bold(true)
+	results := model.shown()
+	row := results.Rows[model.cursorRow]
+	width := model.windowSize.Width - detailIndent
+
+	for i, column := range results.Columns {
+		value := displayValue(row[i], column.TypeName)
+		if width > 0 {
+			value = ansi.Wrap(value, width, "")
+		}
+
+		builder.WriteString(nameStyle.Render(column.Name) + "\n")
+
+		for line := range strings.SplitSeq(value, "\n") {
+			builder.WriteString(strings.Repeat(" ", detailIndent) + line + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// Cursor returns the row and column of the selected cell.
+func (model ResultsPaneModel) Cursor() (int, int) {
+	return model.cursorRow, model.cursorColumn
+}
+
+func (model ResultsPaneModel) moveCursor(rows int, columns int) ResultsPaneModel {
+	if len(model.Results.Rows) == 0 || model.Err != nil {
+		return model
+	}
+
+	model.cursorRow = min(max(model.cursorRow+rows, 0), len(model.Results.Rows)-1)
+	model.cursorColumn = min(max(model.cursorColumn+columns, 0), len(model.Results.Columns)-1)
+
+	content := model.content()
+	model.SearchableViewport.RefreshContent(content.text)
+	model.SearchableViewport.ShowLine(content.cursorLine)
+
+	if model.ViewMode == ResultsViewModeTable && columns != 0 {
+		model.SearchableViewport.ShowColumns(content.cursorLeft, content.cursorRight)
+	}
+
+	return model
+}
+
+// copy asks for the selected cell, the selected row as TSV or JSON, or
+// every row as TSV to be put on the clipboard.
+func (model ResultsPaneModel) copy(key string) tea.Cmd {
+	if len(model.Results.Rows) == 0 || model.Err != nil {
+		return nil
+	}
+
+	columns := model.Results.Columns
+	row := model.shown().Rows[model.cursorRow]
+
+	switch key {
+	case "y":
+		column := columns[model.cursorColumn]
+
+		return dispatch(CopyMsg{
+			Text:        db.FormatValue(row[model.cursorColumn], column.TypeName),
+			Description: column.Name,
+		})
+	case "Y":
+		return dispatch(CopyMsg{Text: format.TSVRow(columns, row), Description: "row as TSV"})
+	case "J":
+		return dispatch(CopyMsg{Text: format.RowJSON(columns, row), Description: "row as JSON"})
+	}
+
+	var builder strings.Builder
+
+	results := model.shown()
+
+	err := format.Write(&builder, format.FormatTSV, results)
+	if err != nil {
+		return dispatch(CopiedMsg{Description: "", Err: err})
+	}
+
+	return dispatch(CopyMsg{
+		Text:        builder.String(),
+		Description: rowCount(len(results.Rows), model.partial()) + " as TSV",
+	})
+}
+
+func (model ResultsPaneModel) startExport() ResultsPaneModel {
+	if len(model.Results.Columns) == 0 {
+		return model
+	}
+
+	if model.exportInput.Value() == "" {
+		model.exportInput.SetValue(defaultExportPath)
+	}
+
+	model.exportInput.CursorEnd()
+	model.exportInput.Focus()
+
+	return model
+}
+
+func (model ResultsPaneModel) updateExport(msg tea.KeyMsg) (ResultsPaneModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		model.exportInput.Blur()
+
+		return model, nil
+	case "enter":
+		model.exportInput.Blur()
+
+		return model, export(model.exportInput.Value(), model.Results, model.partial(), false)
+	}
+
+	var cmd tea.Cmd
+
+	model.exportInput, cmd = model.exportInput.Update(msg)
+
+	return model, cmd
+}
+
+// confirmOverwrite exports again over the existing file when y answers
+// whether to replace it.
+func (model ResultsPaneModel) confirmOverwrite(msg tea.KeyMsg) (ResultsPaneModel, tea.Cmd) {
+	path := model.overwrite
+	model.overwrite = ""
+
+	if msg.String() != "y" {
+		model.notice = "export canceled"
+
+		return model, nil
+	}
+
+	return model, export(path, model.Results, model.partial(), true)
+}
+
+// export writes results to path, picking the format from its extension.
+func export(path string, results db.QueryResult, partial bool, overwrite bool) tea.Cmd {
+	return func() tea.Msg {
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			homeDir, err := os.UserHomeDir()
+			if err == nil {
+				path = filepath.Join(homeDir, rest)
+			}
+		}
+
+		return ExportMsg{
+			Path:    path,
+			Rows:    len(results.Rows),
+			Partial: partial,
+			Err:     format.WriteFile(path, results, overwrite),
+		}
+	}
+}
+
+// startEdit opens the input for a new value for the cursor's cell, when
+// the results can be written back to their table.
+func (model ResultsPaneModel) startEdit() ResultsPaneModel {
+	if len(model.Results.Rows) == 0 || model.Err != nil ||
+		model.statement >= len(model.Statements) {
+		return model
+	}
+
+	editable, err := db.NewEditable(
+		model.Statements[model.statement].Query,
+		model.Results.Columns,
+		model.catalog,
+	)
+	if err == nil {
+		err = editable.CanEdit(model.cursorColumn)
+	}
+
+	if err != nil {
+		model.notice = err.Error()
+
+		return model
+	}
+
+	column := model.Results.Columns[model.cursorColumn]
+	value := model.shown().Rows[model.cursorRow][model.cursorColumn]
+
+	model.editable = &editable
+	model.editInput.Prompt = column.Name + " = "
+	model.editInput.SetValue(db.FormatValue(value, column.TypeName))
+	model.editInput.CursorEnd()
+	model.editInput.Focus()
+
+	return model
+}
+
+func (model ResultsPaneModel) updateEdit(msg tea.KeyMsg) (ResultsPaneModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		model.editInput.Blur()
+
+		return model, nil
+	case "enter":
+		model.editInput.Blur()
+		value := model.editInput.Value()
+
+		return model.stageEdit(&value), nil
+	case "ctrl+n":
+		model.editInput.Blur()
+
+		return model.stageEdit(nil), nil
+	}
+
+	var cmd tea.Cmd
+
+	model.editInput, cmd = model.editInput.Update(msg)
+
+	return model, cmd
+}
+
+// stageEdit sets the cursor's cell to value until the edits are
+// committed or discarded. Setting a cell back to what was read unstages
+// it.
+func (model ResultsPaneModel) stageEdit(value *string) ResultsPaneModel {
+	row, column := model.cursorRow, model.cursorColumn
+	model.edits = slices.DeleteFunc(slices.Clone(model.edits), func(edit cellEdit) bool {
+		return edit.row == row && edit.column == column
+	})
+
+	original := model.Results.Rows[row][column]
+	typeName := model.Results.Columns[column].TypeName
+
+	unchanged := value == nil && original == nil ||
+		value != nil && original != nil && *value == db.FormatValue(original, typeName)
+	if !unchanged {
+		model.edits = append(model.edits, cellEdit{row: row, column: column, value: value})
+	}
+
+	model.SearchableViewport.RefreshContent(model.content().text)
+
+	return model
+}
+
+func (model ResultsPaneModel) discardEdits() ResultsPaneModel {
+	if len(model.edits) == 0 {
+		return model
+	}
+
+	model.notice = "discarded " + editCount(len(model.edits))
+	model.edits = nil
+
+	if model.preview {
+		model.detail = nil
+		model.preview = false
+	}
+
+	model.SearchableViewport.RefreshContent(model.content().text)
+
+	return model
+}
+
+// openPreview shows the UPDATEs the staged edits will run.
+func (model ResultsPaneModel) openPreview() ResultsPaneModel {
+	if len(model.edits) == 0 {
+		model.notice = "no staged edits (e to edit a cell)"
+
+		return model
+	}
+
+	model.preview = true
+	model.plan = nil
+	detail, _ := searchableviewport.NewSearchableViewportModel().Update(model.windowSize)
+	detail.SetContent(model.detailContent())
+	model.detail = &detail
+
+	return model
+}
+
+func (model ResultsPaneModel) previewView() string {
+	return strings.Join(model.updates(), ";\n") + ";\n"
+}
+
+// updates returns an UPDATE for each staged edit, finding rows by the
+// primary key values they were read with.
+func (model ResultsPaneModel) updates() []string {
+	statements := make([]string, len(model.edits))
+	for i, edit := range model.edits {
+		statements[i] = model.editable.Update(
+			model.Results.Rows[edit.row],
+			edit.column,
+			edit.value,
+		)
+	}
+
+	return statements
+}
+
+// commitEdits writes committed edits into the rows read, so they show
+// what the table now holds, and unstages them.
+func (model ResultsPaneModel) commitEdits(committed []cellEdit) ResultsPaneModel {
+	for _, edit := range committed {
+		typeName := model.Results.Columns[edit.column].TypeName
+		model.Results.Rows[edit.row][edit.column] = edit.parse(typeName)
+	}
+
+	model.edits = slices.DeleteFunc(slices.Clone(model.edits), func(edit cellEdit) bool {
+		return slices.ContainsFunc(committed, edit.equal)
+	})
+	model.notice = "committed " + editCount(len(committed))
+	model.detail = nil
+	model.preview = false
+	model.SearchableViewport.RefreshContent(model.content().text)
+
+	return model
+}
+
+// shown returns the results with staged edits in place of the values
+// they change.
+func (model ResultsPaneModel) shown() db.QueryResult {
+	if len(model.edits) == 0 {
+		return model.Results
+	}
+
+	results := model.Results
+	results.Rows = slices.Clone(results.Rows)
+
+	for _, edit := range model.edits {
+		row := slices.Clone(results.Rows[edit.row])
+		row[edit.column] = edit.parse(results.Columns[edit.column].TypeName)
+		results.Rows[edit.row] = row
+	}
+
+	return results
+}
+
+// parse returns the edit's value the way a query would have read it:
+// nil for NULL, decoded JSON for json columns and text otherwise.
+func (edit cellEdit) parse(typeName string) any {
+	if edit.value == nil {
+		return nil
+	}
+
+	var value any
+	if db.IsJSONType(typeName) && json.Unmarshal([]byte(*edit.value), &value) == nil {
+		return value
+	}
+
+	return *edit.value
+}
+
+func (edit cellEdit) equal(other cellEdit) bool {
+	if edit.row != other.row || edit.column != other.column {
+		return false
+	}
+
+	if edit.value == nil || other.value == nil {
+		return edit.value == other.value
+	}
+
+	return *edit.value == *other.value
+}
+
+func editCount(edits int) string {
+	if edits == 1 {
+		return "1 edit"
+	}
+
+	return fmt.Sprintf("%d edits", edits)
+}
+
+func queryTick() tea.Cmd {
+	return tea.Tick(queryTickInterval, func(t time.Time) tea.Msg {
+		return queryTickMsg{time: t}
+	})
+}
+
+// showStatement flips to another statement's result from a
+// multi-statement submission.
+func (model ResultsPaneModel) showStatement(index int) ResultsPaneModel {
+	if index < 0 || index >= len(model.Statements) {
+		return model
+	}
+
+	if len(model.edits) > 0 {
+		model.notice = "commit or discard staged edits first"
+
+		return model
+	}
+
+	statement := model.Statements[index]
+	model.statement = index
+	model.cursorRow = 0
+	model.cursorColumn = 0
+	model.Duration = statement.Duration
+	model.Err = statement.Err
+	model.Results = statement.Results
+	model.truncated = statement.Truncated
+	model.SearchableViewport.SetContent(model.ResultsView())
+
+	return model
+}
+
+// appendRows adds a batch to the last statement, and to the view when
+// it is the statement shown.
+func (model ResultsPaneModel) appendRows(msg RowsMsg) ResultsPaneModel {
+	model.fetchRequested = false
+	model.pending = !msg.Done && msg.Err == nil
+
+	if msg.Err != nil {
+		model.notice = msg.Err.Error()
+	}
+
+	last := len(model.Statements) - 1
+	if last >= 0 {
+		statement := &model.Statements[last]
+		statement.Results.Rows = append(statement.Results.Rows, msg.Rows...)
+		statement.Duration += msg.Duration
+		statement.Truncated = msg.Truncated
+
+		if msg.CommandTag != "" {
+			statement.Results.CommandTag = msg.CommandTag
+		}
+
+		if model.statement != last {
+			return model
+		}
+
+		model.Results = statement.Results
+		model.Duration = statement.Duration
+	} else {
+		model.Results.Rows = append(model.Results.Rows, msg.Rows...)
+		model.Duration += msg.Duration
+	}
+
+	model.truncated = msg.Truncated
+	model.SearchableViewport.RefreshContent(model.ResultsView())
+
+	return model
+}
+
+// streaming reports whether the statement shown has more rows to read.
+func (model ResultsPaneModel) streaming() bool {
+	return model.pending && model.statement >= len(model.Statements)-1
+}
+
+// partial reports whether the result has rows that have not been read,
+// because they are still streaming in or were cut off at max_rows.
+func (model ResultsPaneModel) partial() bool {
+	return model.streaming() || model.truncated
+}
+
+// rowCount describes rows copied or exported, as the first rows of the
+// result when it is partial.
+func rowCount(rows int, partial bool) string {
+	if partial {
+		return "first " + format.RowCount(rows)
+	}
+
+	return format.RowCount(rows)
+}
+
+// wantsRows reports whether the next batch should be asked for, because
+// the cursor or the scroll position is near the rows read so far.
+func (model ResultsPaneModel) wantsRows() bool {
+	if !model.streaming() || model.fetchRequested {
+		return false
+	}
+
+	return model.cursorRow >= len(model.Results.Rows)-fetchAhead ||
+		model.SearchableViewport.AtBottom()
+}
+
+func (model ResultsPaneModel) Running() bool {
+	return model.running
+}
+
+func (model ResultsPaneModel) toggleViewMode() ResultsPaneModel {
+	if model.ViewMode == ResultsViewModeRecord {
+		model.ViewMode = ResultsViewModeTable
+	} else {
+		model.ViewMode = ResultsViewModeRecord
+	}
+
+	model.SearchableViewport.SetContent(model.ResultsView())
+
+	return model
+}
+
 func (model ResultsPaneModel) Focus() ResultsPaneModel {
 	model.focused = true
 
@@ -74,6 +976,10 @@ func (model ResultsPaneModel) Blur() ResultsPaneModel {
 }
 
 func (model ResultsPaneModel) View() string {
+	if model.detail != nil {
+		return fmt.Sprintf("%s\n%s", model.detail.View(), model.detailFooterView())
+	}
+
 	return fmt.Sprintf(
 		"%s\n%s",
 		model.SearchableViewport.View(),
@@ -81,46 +987,222 @@ func (model ResultsPaneModel) View() string {
 	)
 }
 
+func (model ResultsPaneModel) detailFooterView() string {
+	if model.notice != "" {
+		return model.notice
+	}
+
+	if model.detail.FooterView() != "" {
+		return model.detail.FooterView()
+	}
+
+	if model.plan != nil && model.running {
+		return fmt.Sprintf("explaining… %.1fs (esc to cancel)", model.elapsed.Seconds())
+	}
+
+	if model.plan != nil {
+		return "plan (j/k to move, enter to fold, esc to go back)"
+	}
+
+	if model.preview && model.running {
+		return fmt.Sprintf("committing… %.1fs (esc to cancel)", model.elapsed.Seconds())
+	}
+
+	if model.preview {
+		return fmt.Sprintf(
+			"%s staged (enter to commit, D to discard, esc to go back)",
+			editCount(len(model.edits)),
+		)
+	}
+
+	return fmt.Sprintf(
+		"row %d of %d (esc to go back)",
+		model.cursorRow+1,
+		len(model.Results.Rows),
+	)
+}
+
 func (model ResultsPaneModel) ResultsView() string {
+	if errors.Is(model.Err, db.ErrCanceled) {
+		return "query canceled"
+	}
+
 	if model.Err != nil {
 		return model.Err.Error()
 	}
 
+	return model.content().text
+}
+
+// resultsContent is the rendered results and where the cursor's cell
+// landed in them.
+type resultsContent struct {
+	text        string
+	cursorLine  int
+	cursorLeft  int
+	cursorRight int
+}
+
+// content renders the results with the cursor's cell highlighted.
+func (model ResultsPaneModel) content() resultsContent {
+	cursorStyle := lipgloss.NewStyle().Reverse(true)
+	results := model.shown()
+
+	if model.ViewMode == ResultsViewModeTable {
+		text, left, right := format.TableWithOptions(results, format.TableOptions{
+			MaxCellWidth: maxCellWidth,
+			Cursor: &format.Cursor{
+				Row:    model.cursorRow,
+				Column: model.cursorColumn,
+				Style:  cursorStyle,
+			},
+			Null: nullView(),
+		})
+
+		return resultsContent{
+			text: text,
+			// below the header and its separator
+			cursorLine:  model.cursorRow + 2, //nolint:mnd
+			cursorLeft:  left,
+			cursorRight: right,
+		}
+	}
+
 	var builder strings.Builder
 
-	for row := range model.Results {
+	lines := 0
+	cursorLine := 0
+
+	for i, row := range results.Rows {
 		builder.WriteString("---\n")
 
-		keys := make([]string, 0, len(model.Results[row]))
-		for key := range model.Results[row] {
-			keys = append(keys, key)
-		}
+		lines++
+
+		for j, column := range results.Columns {
+			field := column.Name + ": " + displayValue(row[j], column.TypeName)
+			if i == model.cursorRow && j == model.cursorColumn {
+				field = cursorStyle.Render(field)
+				cursorLine = lines
+			}
 
-		sort.Strings(keys)
+			builder.WriteString(field + "\n")
 
-		for _, key := range keys {
-			builder.WriteString(fmt.Sprintf("%s: %v\n", key, model.Results[row][key]))
+			lines += strings.Count(field, "\n") + 1
 		}
 	}
 
-	return builder.String()
+	return resultsContent{
+		text:        builder.String(),
+		cursorLine:  cursorLine,
+		cursorLeft:  0,
+		cursorRight: 0,
+	}
+}
+
+// displayValue renders a value in its Postgres text form, with NULL
+// styled apart from an empty string and JSON indented to read.
+func displayValue(value any, typeName string) string {
+	if value == nil {
+		return nullView()
+	}
+
+	text := db.FormatValue(value, typeName)
+	if !db.IsJSONType(typeName) {
+		return text
+	}
+
+	var buffer bytes.Buffer
+
+	err := json.Indent(&buffer, []byte(text), "", "  ")
+	if err != nil {
+		return text
+	}
+
+	return buffer.String()
+}
+
+func nullView() string {
+	return lipgloss.NewStyle().Faint(true).Italic(true).Render("NULL")
 }
 
 func (model ResultsPaneModel) footerView() string {
+	if model.exportInput.Focused() {
+		return model.exportInput.View()
+	}
+
+	if model.overwrite != "" {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("1")).
+			Render(model.overwrite + " exists, y to overwrite it, any other key to cancel")
+	}
+
+	if model.editInput.Focused() {
+		return model.editInput.View()
+	}
+
+	if model.notice != "" {
+		return model.notice
+	}
+
 	if model.SearchableViewport.FooterView() != "" && model.focused {
 		return model.SearchableViewport.FooterView()
 	}
 
+	if model.running {
+		return fmt.Sprintf("running… %.1fs (esc to cancel)", model.elapsed.Seconds())
+	}
+
+	if len(model.edits) > 0 {
+		return fmt.Sprintf(
+			"%s%s staged (p to preview, D to discard)",
+			model.statementView(),
+			editCount(len(model.edits)),
+		)
+	}
+
 	if model.Duration.Seconds() == 0 {
 		return ""
 	}
 
-	numStr := "1 row"
+	if errors.Is(model.Err, db.ErrCanceled) {
+		return fmt.Sprintf("%s(canceled after %.3fs)", model.statementView(), model.Duration.Seconds())
+	}
+
+	if len(model.Results.Columns) == 0 && model.Results.CommandTag != "" {
+		return fmt.Sprintf(
+			"%s(%s in %.3fs)",
+			model.statementView(),
+			model.Results.CommandTag,
+			model.Duration.Seconds(),
+		)
+	}
+
+	rows := format.RowCount(len(model.Results.Rows))
+
+	switch {
+	case model.streaming():
+		rows += " so far"
+	case model.truncated:
+		return fmt.Sprintf(
+			"%s(%s in %.3fs, truncated at max_rows)",
+			model.statementView(),
+			rows,
+			model.Duration.Seconds(),
+		)
+	}
+
+	return fmt.Sprintf(
+		"%s(%s in %.3fs)",
+		model.statementView(),
+		rows,
+		model.Duration.Seconds(),
+	)
+}
 
-	numResults := len(model.Results)
-	if numResults != 1 {
-		numStr = fmt.Sprintf("%d rows", numResults)
+func (model ResultsPaneModel) statementView() string {
+	if len(model.Statements) <= 1 {
+		return ""
 	}
 
-	return fmt.Sprintf("(%s in %.3fs)", numStr, model.Duration.Seconds())
+	return fmt.Sprintf("[%d/%d] ", model.statement+1, len(model.Statements))
 }
@@ -1,13 +1,21 @@
 package ui_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jshawl/dbq/internal/db"
+	"github.com/jshawl/dbq/internal/format"
+	"github.com/jshawl/dbq/internal/searchableviewport"
+	"github.com/jshawl/dbq/internal/testutil"
 	"github.com/jshawl/dbq/internal/ui"
 )
 
@@ -21,29 +29,171 @@ func TestResultsPane_Update(t *testing.T) {
 		model := ui.NewResultsPaneModel()
 		updatedModel, _ := model.Update(ui.QueryResponseReceivedMsg{
 			QueryMsg: ui.QueryMsg{
-				Duration: 0,
-				Err:      nil,
-				Results:  makeResults(userID),
-				Query:    "select * from posts",
+				Duration:   0,
+				Err:        nil,
+				Results:    makeResults(userID),
+				Query:      "select * from posts",
+				Statements: nil,
+				TxStatus:   db.TxIdle,
 			},
 		})
 
-		got := updatedModel.Results[0]["id"]
+		got := updatedModel.Results.Rows[0][0]
 		if got != userID {
 			t.Fatalf("expected first result to have id %d got %d", userID, got)
 		}
 	})
 
+	t.Run("keys - t toggles table view", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel().Focus()
+		model.Results = makeResults(123)
+
+		updatedModel, _ := model.Update(testutil.MakeRuneKeyMsg('t'))
+		if updatedModel.ViewMode != ui.ResultsViewModeTable {
+			t.Fatal("expected t to switch to table view")
+		}
+
+		updatedModel, _ = updatedModel.Update(testutil.MakeRuneKeyMsg('t'))
+		if updatedModel.ViewMode != ui.ResultsViewModeRecord {
+			t.Fatal("expected t to switch back to record view")
+		}
+	})
+
+	t.Run("keys - t while searching", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel().Focus()
+		model.SearchableViewport.Search = model.SearchableViewport.Search.Focus()
+
+		updatedModel, _ := model.Update(testutil.MakeRuneKeyMsg('t'))
+		if updatedModel.ViewMode != ui.ResultsViewModeRecord {
+			t.Fatal("expected t to be typed into the search input")
+		}
+	})
+
+	t.Run("QueryStartedMsg", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		updatedModel, cmd := model.Update(ui.QueryStartedMsg{StartedAt: time.Now()})
+
+		if !updatedModel.Running() {
+			t.Fatal("expected results pane to be running")
+		}
+
+		if cmd == nil {
+			t.Fatal("expected QueryStartedMsg to start ticking")
+		}
+
+		if !strings.Contains(updatedModel.View(), "running… 0.0s") {
+			t.Fatalf("expected running indicator, got %s", updatedModel.View())
+		}
+
+		updatedModel, _ = updatedModel.Update(ui.QueryResponseReceivedMsg{
+			QueryMsg: ui.QueryMsg{
+				Duration:   time.Millisecond * 1500,
+				Err:        nil,
+				Results:    makeResults(1),
+				Query:      "select 1",
+				Statements: nil,
+				TxStatus:   db.TxIdle,
+			},
+		})
+
+		if updatedModel.Running() {
+			t.Fatal("expected results pane to stop running")
+		}
+	})
+
+	t.Run("keys - [ and ] flip statements", func(t *testing.T) {
+		t.Parallel()
+
+		first := db.DBQueryResult{
+			Err:       nil,
+			Results:   makeResults(1),
+			Duration:  time.Millisecond * 1000,
+			Query:     "select 1",
+			Truncated: false,
+		}
+		second := db.DBQueryResult{
+			Err:       nil,
+			Results:   makeResults(2, 3),
+			Duration:  time.Millisecond * 2000,
+			Query:     "select 2",
+			Truncated: false,
+		}
+
+		model := ui.NewResultsPaneModel().Focus()
+		model, _ = model.Update(ui.QueryResponseReceivedMsg{
+			QueryMsg: ui.QueryMsg{
+				Duration:   time.Millisecond * 3000,
+				Err:        nil,
+				Results:    second.Results,
+				Query:      "select 1; select 2",
+				Statements: []db.DBQueryResult{first, second},
+				TxStatus:   db.TxIdle,
+			},
+		})
+
+		if !strings.Contains(model.View(), "[2/2] (2 rows in 2.000s)") {
+			t.Fatalf("expected last statement to be shown, got %s", model.View())
+		}
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('['))
+		if !strings.Contains(model.View(), "[1/2] (1 row in 1.000s)") {
+			t.Fatalf("expected first statement to be shown, got %s", model.View())
+		}
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('['))
+		if !strings.Contains(model.View(), "[1/2]") {
+			t.Fatalf("expected [ to stop at the first statement, got %s", model.View())
+		}
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg(']'))
+		if model.Results.Rows[0][0] != 2 {
+			t.Fatalf("expected ] to show the second statement, got %v", model.Results.Rows)
+		}
+	})
+
+	t.Run("QueryResponseReceivedMsg - canceled", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		updatedModel, _ := model.Update(ui.QueryResponseReceivedMsg{
+			QueryMsg: ui.QueryMsg{
+				Duration:   time.Millisecond * 1500,
+				Err:        fmt.Errorf("%w: %w", db.ErrCanceled, context.Canceled),
+				Results:    db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
+				Query:      "select pg_sleep(10)",
+				Statements: nil,
+				TxStatus:   db.TxIdle,
+			},
+		})
+
+		if updatedModel.ResultsView() != "query canceled" {
+			t.Fatalf("expected cancellation to be visible, got %s", updatedModel.ResultsView())
+		}
+
+		view := updatedModel.View()
+		if !strings.Contains(view, "(canceled after 1.500s)") {
+			t.Fatalf("expected cancellation footer, got %s", view)
+		}
+	})
+
 	t.Run("QueryResponseReceivedMsg - err", func(t *testing.T) {
 		t.Parallel()
 
 		model := ui.NewResultsPaneModel()
 		updatedModel, _ := model.Update(ui.QueryResponseReceivedMsg{
 			QueryMsg: ui.QueryMsg{
-				Duration: 0,
-				Err:      errSQL,
-				Results:  db.QueryResult{},
-				Query:    "not sql",
+				Duration:   0,
+				Err:        errSQL,
+				Results:    db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
+				Query:      "not sql",
+				Statements: nil,
+				TxStatus:   db.TxIdle,
 			},
 		})
 
@@ -51,6 +201,630 @@ func TestResultsPane_Update(t *testing.T) {
 			t.Fatal("expected query msg err to update model")
 		}
 	})
+
+	t.Run("keys - x exports results", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "posts.csv")
+		model := ui.NewResultsPaneModel().Focus()
+		model.Results = makeResults(123)
+
+		updatedModel, _ := model.Update(testutil.MakeRuneKeyMsg('x'))
+		if !strings.Contains(updatedModel.View(), "export to: results.csv") {
+			t.Fatalf("expected export prompt, got %s", updatedModel.View())
+		}
+
+		for range len("results.csv") {
+			updatedModel, _ = updatedModel.Update(testutil.MakeKeyMsg(tea.KeyBackspace))
+		}
+
+		for _, r := range path {
+			updatedModel, _ = updatedModel.Update(testutil.MakeRuneKeyMsg(r))
+		}
+
+		updatedModel, cmd := updatedModel.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+		msg := testutil.AssertMsgType[ui.ExportMsg](t, cmd)
+
+		if msg.Err != nil || msg.Path != path || msg.Rows != 1 {
+			t.Fatalf("unexpected export msg %+v", msg)
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(string(contents), "id,created_at\n123,") {
+			t.Fatalf("unexpected export %q", contents)
+		}
+
+		updatedModel, _ = updatedModel.Update(msg)
+		if !strings.Contains(updatedModel.View(), "exported 1 row to "+path) {
+			t.Fatalf("expected export notice, got %s", updatedModel.View())
+		}
+	})
+
+	t.Run("keys - x asks before overwriting a file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "posts.csv")
+
+		err := os.WriteFile(path, []byte("keep me"), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		model := ui.NewResultsPaneModel().Focus()
+		model.Results = makeResults(123)
+
+		updatedModel, _ := model.Update(ui.ExportMsg{
+			Path: path,
+			Rows: 1,
+			Err:  fmt.Errorf("%w: %s", format.ErrExists, path),
+		})
+		if !strings.Contains(updatedModel.View(), path+" exists, y to overwrite it") {
+			t.Fatalf("expected an overwrite prompt, got %s", updatedModel.View())
+		}
+
+		canceled, cmd := updatedModel.Update(testutil.MakeRuneKeyMsg('n'))
+		if cmd != nil || !strings.Contains(canceled.View(), "export canceled") {
+			t.Fatalf("expected any other key to cancel, got %s", canceled.View())
+		}
+
+		_, cmd = updatedModel.Update(testutil.MakeRuneKeyMsg('y'))
+		msg := testutil.AssertMsgType[ui.ExportMsg](t, cmd)
+
+		contents, err := os.ReadFile(path)
+		if msg.Err != nil || err != nil || !strings.HasPrefix(string(contents), "id,created_at\n") {
+			t.Fatalf("expected y to overwrite, got %q, %v, %v", contents, msg.Err, err)
+		}
+	})
+
+	t.Run("keys - esc cancels export", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel().Focus()
+		model.Results = makeResults(123)
+
+		updatedModel, _ := model.Update(testutil.MakeRuneKeyMsg('x'))
+		updatedModel, cmd := updatedModel.Update(testutil.MakeKeyMsg(tea.KeyEsc))
+
+		if cmd != nil || strings.Contains(updatedModel.View(), "export to:") {
+			t.Fatalf("expected esc to close the export prompt, got %s", updatedModel.View())
+		}
+	})
+
+	t.Run("keys - hjkl move the cursor", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel().Focus()
+		model.Results = makeResults(123, 456)
+
+		for _, r := range "jjlll" {
+			model, _ = model.Update(testutil.MakeRuneKeyMsg(r))
+		}
+
+		row, column := model.Cursor()
+		if row != 1 || column != 1 {
+			t.Fatalf("expected cursor clamped to 1, 1, got %d, %d", row, column)
+		}
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('h'))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('k'))
+
+		row, column = model.Cursor()
+		if row != 0 || column != 0 {
+			t.Fatalf("expected cursor back at 0, 0, got %d, %d", row, column)
+		}
+	})
+
+	t.Run("keys - y copies", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel().Focus()
+		model.Results = makeResults(123, 456)
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('j'))
+
+		tests := []struct {
+			key  rune
+			want ui.CopyMsg
+		}{
+			{key: 'y', want: ui.CopyMsg{Text: "456", Description: "id"}},
+			{
+				key:  'Y',
+				want: ui.CopyMsg{Text: "456\t2025-09-21T15:41:22", Description: "row as TSV"},
+			},
+			{
+				key: 'J',
+				want: ui.CopyMsg{
+					Text:        `{"id":456,"created_at":"2025-09-21T15:41:22"}`,
+					Description: "row as JSON",
+				},
+			},
+			{
+				key: 'A',
+				want: ui.CopyMsg{
+					Text: "id\tcreated_at\n" +
+						"123\t2025-09-21T15:41:22\n" +
+						"456\t2025-09-21T15:41:22\n",
+					Description: "2 rows as TSV",
+				},
+			},
+		}
+
+		for _, test := range tests {
+			_, cmd := model.Update(testutil.MakeRuneKeyMsg(test.key))
+
+			got := testutil.AssertMsgType[ui.CopyMsg](t, cmd)
+			if got != test.want {
+				t.Errorf("%c: expected %+v, got %+v", test.key, test.want, got)
+			}
+		}
+	})
+
+	t.Run("keys - y without results", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel().Focus()
+
+		_, cmd := model.Update(testutil.MakeRuneKeyMsg('y'))
+		if cmd != nil {
+			t.Fatal("expected nothing to copy")
+		}
+	})
+
+	t.Run("CopiedMsg", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		updatedModel, _ := model.Update(ui.CopiedMsg{Description: "row as JSON", Err: nil})
+
+		if !strings.Contains(updatedModel.View(), "copied row as JSON") {
+			t.Fatalf("expected copy notice, got %s", updatedModel.View())
+		}
+	})
+
+	t.Run("keys - enter opens the detail view", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel().Focus()
+		model, _ = model.Update(searchableviewport.WindowSizeMsg{Height: 10, Width: 20})
+		model.Results = db.QueryResult{
+			Columns: []db.Column{
+				{Name: "id", TypeOID: 23, TypeName: "int4"},
+				{Name: "body", TypeOID: 25, TypeName: "text"},
+			},
+			Rows: [][]interface{}{
+				{1, "short"},
+				{2, strings.Repeat("word ", 6)},
+			},
+			CommandTag: "SELECT 2",
+		}
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('j'))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		if !model.DetailOpen() {
+			t.Fatal("expected enter to open the detail view")
+		}
+
+		view := model.View()
+		if strings.Count(view, "  word word word") != 2 ||
+			!strings.Contains(view, "row 2 of 2 (esc to go back)") {
+			t.Fatalf("expected the second row wrapped in full, got \n%s", view)
+		}
+
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEsc))
+
+		if model.DetailOpen() {
+			t.Fatal("expected esc to close the detail view")
+		}
+
+		row, _ := model.Cursor()
+		if row != 1 {
+			t.Fatalf("expected the cursor to stay on row 1, got %d", row)
+		}
+	})
+
+	t.Run("keys - enter without results", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel().Focus()
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		if model.DetailOpen() {
+			t.Fatal("expected no detail view without rows")
+		}
+	})
+
+	t.Run("RowsMsg appends a batch", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		model, _ = model.Update(ui.QueryResponseReceivedMsg{
+			QueryMsg: ui.QueryMsg{
+				Duration:   time.Second,
+				Err:        nil,
+				Results:    makeResults(1),
+				Query:      "select * from users",
+				Statements: nil,
+				TxStatus:   db.TxIdle,
+			},
+		})
+
+		model, cmd := model.Update(ui.RowsMsg{
+			Rows:       makeResults(2).Rows,
+			Done:       false,
+			Truncated:  false,
+			CommandTag: "",
+			Duration:   time.Second,
+			Err:        nil,
+			TxStatus:   db.TxIdle,
+		})
+
+		if len(model.Results.Rows) != 2 {
+			t.Fatalf("expected the batch to be appended, got %v", model.Results.Rows)
+		}
+
+		if !strings.Contains(model.View(), "(2 rows so far in 2.000s)") {
+			t.Fatalf("expected more rows to be pending, got %s", model.View())
+		}
+
+		testutil.AssertMsgType[ui.FetchRowsMsg](t, cmd)
+
+		model, _ = model.Update(ui.RowsMsg{
+			Rows:       makeResults(3).Rows,
+			Done:       true,
+			Truncated:  true,
+			CommandTag: "",
+			Duration:   0,
+			Err:        nil,
+			TxStatus:   db.TxIdle,
+		})
+
+		if !strings.Contains(model.View(), "(3 rows in 2.000s, truncated at max_rows)") {
+			t.Fatalf("expected a truncated notice, got %s", model.View())
+		}
+	})
+
+	t.Run("keys - A and x say when rows are left unread", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel().Focus()
+		model, _ = model.Update(ui.QueryResponseReceivedMsg{
+			QueryMsg: ui.QueryMsg{
+				Duration:   time.Second,
+				Err:        nil,
+				Results:    makeResults(1),
+				Query:      "select * from users",
+				Statements: nil,
+				TxStatus:   db.TxIdle,
+			},
+		})
+		model, _ = model.Update(ui.RowsMsg{
+			Rows:       makeResults(2).Rows,
+			Done:       false,
+			Truncated:  false,
+			CommandTag: "",
+			Duration:   0,
+			Err:        nil,
+			TxStatus:   db.TxIdle,
+		})
+
+		_, cmd := model.Update(testutil.MakeRuneKeyMsg('A'))
+		if msg := testutil.AssertMsgType[ui.CopyMsg](t, cmd); msg.Description != "first 2 rows as TSV" {
+			t.Fatalf("expected the copy labeled partial, got %q", msg.Description)
+		}
+
+		model, _ = model.Update(ui.RowsMsg{
+			Rows:       nil,
+			Done:       true,
+			Truncated:  true,
+			CommandTag: "",
+			Duration:   0,
+			Err:        nil,
+			TxStatus:   db.TxIdle,
+		})
+
+		path := filepath.Join(t.TempDir(), "users.csv")
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('x'))
+		for range len("results.csv") {
+			model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyBackspace))
+		}
+
+		for _, r := range path {
+			model, _ = model.Update(testutil.MakeRuneKeyMsg(r))
+		}
+
+		model, cmd = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+		model, _ = model.Update(testutil.AssertMsgType[ui.ExportMsg](t, cmd))
+
+		if !strings.Contains(model.View(), "exported first 2 rows to "+path) {
+			t.Fatalf("expected the export labeled partial, got %s", model.View())
+		}
+	})
+
+	t.Run("RowsMsg for an earlier statement's view", func(t *testing.T) {
+		t.Parallel()
+
+		first := db.DBQueryResult{
+			Err:       nil,
+			Results:   makeResults(1),
+			Duration:  0,
+			Query:     "select 1",
+			Truncated: false,
+		}
+		second := first
+		second.Results = makeResults(2)
+
+		model := ui.NewResultsPaneModel().Focus()
+		model, _ = model.Update(ui.QueryResponseReceivedMsg{
+			QueryMsg: ui.QueryMsg{
+				Duration:   0,
+				Err:        nil,
+				Results:    second.Results,
+				Query:      "select 1; select 2",
+				Statements: []db.DBQueryResult{first, second},
+				TxStatus:   db.TxIdle,
+			},
+		})
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('['))
+		model, _ = model.Update(ui.RowsMsg{
+			Rows:       makeResults(3).Rows,
+			Done:       true,
+			Truncated:  false,
+			CommandTag: "SELECT 2",
+			Duration:   0,
+			Err:        nil,
+			TxStatus:   db.TxIdle,
+		})
+
+		if len(model.Results.Rows) != 1 || len(model.Statements[1].Results.Rows) != 2 {
+			t.Fatalf("expected the batch on the last statement only, got %+v", model.Statements)
+		}
+	})
+
+	t.Run("keys - e stages edits and p previews them", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupEditableResultsPane(t)
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('l'))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('e'))
+
+		if !strings.Contains(model.View(), "email = a@example.com") {
+			t.Fatalf("expected the cell's value to edit, got %s", model.View())
+		}
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('m'))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('j'))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('e'))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('b'))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		view := model.View()
+		if !strings.Contains(view, "a@example.comm") ||
+			!strings.Contains(view, "2 edits staged (p to preview, D to discard)") {
+			t.Fatalf("expected staged values in place, got %s", view)
+		}
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('p'))
+
+		view = model.View()
+		if !strings.Contains(view, "UPDATE users SET email = 'a@example.comm' WHERE id = 1;") ||
+			!strings.Contains(view, "UPDATE users SET email = 'b' WHERE id = 2;") {
+			t.Fatalf("expected a preview of the updates, got %s", view)
+		}
+
+		_, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		msg := testutil.AssertMsgType[ui.CommitEditsMsg](t, cmd)
+		if len(msg.Statements) != 2 {
+			t.Fatalf("expected 2 updates to commit, got %v", msg.Statements)
+		}
+	})
+
+	t.Run("keys - A copies staged edits", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupEditableResultsPane(t)
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('l'))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('e'))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('m'))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		_, cmd := model.Update(testutil.MakeRuneKeyMsg('A'))
+
+		msg := testutil.AssertMsgType[ui.CopyMsg](t, cmd)
+		if !strings.Contains(msg.Text, "a@example.comm") {
+			t.Fatalf("expected the staged value to be copied, got %q", msg.Text)
+		}
+	})
+
+	t.Run("keys - D discards edits", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupEditableResultsPane(t)
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('l'))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('e'))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyCtrlN))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('D'))
+
+		view := model.View()
+		if !strings.Contains(view, "discarded 1 edit") || !strings.Contains(view, "a@example.com") {
+			t.Fatalf("expected the edit to be discarded, got %s", view)
+		}
+	})
+
+	t.Run("keys - e on a column that is not editable", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupEditableResultsPane(t)
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('e'))
+
+		if !strings.Contains(model.View(), "id is part of the primary key") {
+			t.Fatalf("expected the key not to be editable, got %s", model.View())
+		}
+	})
+
+	t.Run("EditsCommittedMsg - err", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupEditableResultsPane(t)
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('l'))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('e'))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyCtrlN))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('p'))
+		model, _ = model.Update(ui.EditsCommittedMsg{Err: db.ErrApplyUpdates})
+
+		if !model.DetailOpen() || !strings.Contains(model.View(), db.ErrApplyUpdates.Error()) {
+			t.Fatalf("expected the preview to show the error, got %s", model.View())
+		}
+	})
+
+	t.Run("ExplainedMsg shows the plan as a tree", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel().Focus()
+		model, _ = model.Update(searchableviewport.WindowSizeMsg{Height: 10, Width: 200})
+		model, _ = model.Update(ui.ExplainedMsg{Plan: makePlan(), Err: nil})
+
+		view := model.View()
+		for _, want := range []string{
+			"planning 0.100 ms, execution 4.000 ms",
+			"▾ Hash Join  cost=1.00..30.00 rows=10  actual 4.000 ms rows=10 loops=1  self 1.000 ms",
+			"\n  Hash Cond: (p.user_id = u.id)",
+			"    Seq Scan on posts p  cost=0.00..20.00 rows=10",
+			"plan (j/k to move, enter to fold, esc to go back)",
+		} {
+			if !strings.Contains(view, want) {
+				t.Fatalf("expected %q in the plan, got\n%s", want, view)
+			}
+		}
+
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+		if view := model.View(); !strings.Contains(view, "▸ Hash Join") ||
+			strings.Contains(view, "Seq Scan") {
+			t.Fatalf("expected enter to fold the join, got\n%s", view)
+		}
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('l'))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('j'))
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+		if !strings.Contains(model.View(), "Seq Scan on posts p") {
+			t.Fatalf("expected l to unfold the join and enter to leave a leaf alone, got\n%s",
+				model.View())
+		}
+
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEsc))
+		if model.DetailOpen() {
+			t.Fatal("expected esc to close the plan")
+		}
+	})
+
+	t.Run("ExplainedMsg - err", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		model, _ = model.Update(ui.ExplainedMsg{Plan: db.Plan{}, Err: db.ErrExplainAnalyze})
+
+		if model.DetailOpen() || !strings.Contains(model.View(), db.ErrExplainAnalyze.Error()) {
+			t.Fatalf("expected the error, got %s", model.View())
+		}
+	})
+
+	t.Run("ExportMsg - err", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		updatedModel, _ := model.Update(ui.ExportMsg{
+			Path: "results.xlsx",
+			Rows: 1,
+			Err:  format.ErrUnknownFormat,
+		})
+
+		if !strings.Contains(updatedModel.View(), format.ErrUnknownFormat.Error()) {
+			t.Fatalf("expected export error, got %s", updatedModel.View())
+		}
+	})
+}
+
+// makePlan returns an analyzed plan joining posts to users, most of whose
+// time goes to scanning posts.
+func makePlan() db.Plan {
+	scan := func(operation string, target string, cost float64, time float64) db.PlanNode {
+		return db.PlanNode{
+			Operation:   operation,
+			Target:      target,
+			Details:     nil,
+			StartupCost: 0,
+			TotalCost:   cost,
+			PlanRows:    10,
+			ActualTime:  time,
+			ActualRows:  10,
+			ActualLoops: 1,
+			Children:    nil,
+		}
+	}
+
+	return db.Plan{
+		Nodes: []db.PlanNode{{
+			Operation:   "Hash Join",
+			Target:      "",
+			Details:     []string{"Hash Cond: (p.user_id = u.id)"},
+			StartupCost: 1,
+			TotalCost:   30,
+			PlanRows:    10,
+			ActualTime:  4,
+			ActualRows:  10,
+			ActualLoops: 1,
+			Children: []db.PlanNode{
+				scan("Seq Scan", "on posts p", 20, 2.5),
+				scan("Seq Scan", "on users u", 9, 0.5),
+			},
+		}},
+		Costs:         true,
+		Analyzed:      true,
+		PlanningTime:  0.1,
+		ExecutionTime: 4,
+	}
+}
+
+// setupEditableResultsPane shows rows read from the catalog's users table.
+func setupEditableResultsPane(t *testing.T) ui.ResultsPaneModel {
+	t.Helper()
+
+	results := db.QueryResult{
+		Columns: []db.Column{
+			{Name: "id", TypeOID: 23, TypeName: "int4", TableOID: usersOID, TableAttributeNumber: 1},
+			{Name: "email", TypeOID: 25, TypeName: "text", TableOID: usersOID, TableAttributeNumber: 2},
+		},
+		Rows:       [][]interface{}{{1, "a@example.com"}, {2, nil}},
+		CommandTag: "SELECT 2",
+	}
+
+	model := ui.NewResultsPaneModel().Focus()
+	model, _ = model.Update(searchableviewport.WindowSizeMsg{Height: 10, Width: 80})
+	model, _ = model.Update(ui.CatalogMsg{Catalog: makeCatalog(), Err: nil})
+	model, _ = model.Update(ui.QueryResponseReceivedMsg{
+		QueryMsg: ui.QueryMsg{
+			Duration: time.Second,
+			Err:      nil,
+			Results:  results,
+			Query:    "select * from users",
+			TxStatus: db.TxIdle,
+			Statements: []db.DBQueryResult{{
+				Err:       nil,
+				Results:   results,
+				Duration:  time.Second,
+				Query:     "select * from users",
+				Truncated: false,
+			}},
+		},
+	})
+
+	return model
 }
 
 func TestResultsPane_View(t *testing.T) {
@@ -69,6 +843,19 @@ func TestResultsPane_View(t *testing.T) {
 		}
 	})
 
+	t.Run("command tag without a result set", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		model.Duration = time.Millisecond * 4
+		model.Results = db.QueryResult{Columns: nil, Rows: nil, CommandTag: "UPDATE 5"}
+
+		view := model.View()
+		if !strings.Contains(view, "(UPDATE 5 in 0.004s)") {
+			t.Fatalf("expected command tag to be visible\n %s", view)
+		}
+	})
+
 	t.Run("duration with 2 rows", func(t *testing.T) {
 		t.Parallel()
 
@@ -95,7 +882,7 @@ func TestResultsPane_ResultsView(t *testing.T) {
 		view := model.ResultsView()
 
 		matched, _ := regexp.MatchString(
-			`---\ncreated_at: 2025-09-21T15:41:22\nid: 666`,
+			`---\nid: 666\ncreated_at: 2025-09-21T15:41:22`,
 			view,
 		)
 		if !matched {
@@ -103,6 +890,118 @@ func TestResultsPane_ResultsView(t *testing.T) {
 		}
 	})
 
+	t.Run("duplicate columns in select order", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		model.Results = db.QueryResult{
+			Columns: []db.Column{
+				{Name: "name", TypeOID: 25, TypeName: "text"},
+				{Name: "id", TypeOID: 23, TypeName: "int4"},
+				{Name: "id", TypeOID: 23, TypeName: "int4"},
+			},
+			Rows: [][]interface{}{
+				{"john", 1, 2},
+			},
+			CommandTag: "SELECT 1",
+		}
+
+		view := model.ResultsView()
+		if view != "---\nname: john\nid: 1\nid: 2\n" {
+			t.Fatalf("expected columns in select order, got \n %s", view)
+		}
+	})
+
+	t.Run("postgres types", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		model.Results = db.QueryResult{
+			Columns: []db.Column{
+				{Name: "id", TypeOID: 2950, TypeName: "uuid"},
+				{Name: "deleted_at", TypeOID: 1184, TypeName: "timestamptz"},
+				{Name: "payload", TypeOID: 3802, TypeName: "jsonb"},
+				{Name: "tags", TypeOID: 1009, TypeName: "_text"},
+			},
+			Rows: [][]interface{}{{
+				[16]byte{
+					0xa0, 0xee, 0xbc, 0x99, 0x9c, 0x0b, 0x4e, 0xf8,
+					0xbb, 0x6d, 0x6b, 0xb9, 0xbd, 0x38, 0x0a, 0x11,
+				},
+				nil,
+				map[string]any{"a": 1},
+				[]any{"x", "y z"},
+			}},
+			CommandTag: "SELECT 1",
+		}
+
+		want := "---\n" +
+			"id: a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11\n" +
+			"deleted_at: NULL\n" +
+			"payload: {\n  \"a\": 1\n}\n" +
+			"tags: {x,\"y z\"}\n"
+
+		view := model.ResultsView()
+		if view != want {
+			t.Fatalf("expected values in their text form, got \n%s", view)
+		}
+	})
+
+	t.Run("table shows NULL", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		model.ViewMode = ui.ResultsViewModeTable
+		model.Results = db.QueryResult{
+			Columns:    []db.Column{{Name: "email", TypeOID: 25, TypeName: "text"}},
+			Rows:       [][]interface{}{{nil}, {""}},
+			CommandTag: "SELECT 2",
+		}
+
+		want := " email\n-------\n NULL\n\n"
+
+		view := model.ResultsView()
+		if view != want {
+			t.Fatalf("expected NULL apart from the empty string, got \n%q", view)
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		model.ViewMode = ui.ResultsViewModeTable
+		model.Results = makeResults(7, 456)
+
+		want := "" +
+			" id  | created_at\n" +
+			"-----+---------------------\n" +
+			"   7 | 2025-09-21T15:41:22\n" +
+			" 456 | 2025-09-21T15:41:22\n"
+
+		view := model.ResultsView()
+		if view != want {
+			t.Fatalf("expected aligned table, got \n%s", view)
+		}
+	})
+
+	t.Run("table truncates long cells", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewResultsPaneModel()
+		model.ViewMode = ui.ResultsViewModeTable
+		model.Results = db.QueryResult{
+			Columns:    []db.Column{{Name: "content", TypeOID: 25, TypeName: "text"}},
+			Rows:       [][]interface{}{{strings.Repeat("a", 100) + "\nb"}},
+			CommandTag: "SELECT 1",
+		}
+
+		view := model.ResultsView()
+		if !strings.Contains(view, strings.Repeat("a", 39)+"…\n") {
+			t.Fatalf("expected long cell to be truncated, got \n%s", view)
+		}
+	})
+
 	t.Run("errors", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,296 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/jshawl/dbq/internal/db"
+)
+
+type SchemaPaneModel struct {
+	Catalog db.Catalog
+	Err     error
+
+	cursor   int
+	offset   int
+	expanded map[string]bool
+	focused  bool
+	width    int
+	height   int
+}
+
+type CatalogMsg struct {
+	Catalog db.Catalog
+	Err     error
+}
+
+type RefreshCatalogMsg struct{}
+
+// InsertQueryMsg replaces the query pane text and focuses it.
+type InsertQueryMsg struct {
+	Value string
+}
+
+type schemaRow struct {
+	key   string
+	depth int
+	text  string
+	table *db.Table
+}
+
+const (
+	schemaPaneWidth = 36
+	selectLimit     = 100
+)
+
+func NewSchemaPaneModel() SchemaPaneModel {
+	return SchemaPaneModel{
+		Catalog: db.Catalog{Schemas: nil, Functions: nil},
+		Err:     nil,
+
+		cursor:   0,
+		offset:   0,
+		expanded: map[string]bool{},
+		focused:  false,
+		width:    schemaPaneWidth,
+		height:   0,
+	}
+}
+
+func (model SchemaPaneModel) Update(msg tea.Msg) (SchemaPaneModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case CatalogMsg:
+		model.Catalog = msg.Catalog
+		model.Err = msg.Err
+
+		if len(model.expanded) == 0 {
+			model.expanded = map[string]bool{}
+			for _, schema := range msg.Catalog.Schemas {
+				model.expanded[schemaKey(schema.Name)] = true
+			}
+		}
+
+		return model.moveCursor(0), nil
+	case tea.KeyMsg:
+		if !model.focused {
+			return model, nil
+		}
+
+		return model.handleKey(msg)
+	}
+
+	return model, nil
+}
+
+func (model SchemaPaneModel) handleKey(msg tea.KeyMsg) (SchemaPaneModel, tea.Cmd) {
+	rows := model.rows()
+
+	switch msg.String() {
+	case "up", "k":
+		return model.moveCursor(-1), nil
+	case "down", "j":
+		return model.moveCursor(1), nil
+	case "r":
+		return model, dispatch(RefreshCatalogMsg{})
+	case "enter":
+		if model.cursor < len(rows) && rows[model.cursor].table != nil {
+			return model, dispatch(InsertQueryMsg{
+				Value: selectStatement(*rows[model.cursor].table),
+			})
+		}
+
+		return model.toggle(rows, !model.isExpanded(rows)), nil
+	case " ", "right", "l":
+		return model.toggle(rows, !model.isExpanded(rows)), nil
+	case "left", "h":
+		return model.toggle(rows, false), nil
+	}
+
+	return model, nil
+}
+
+func (model SchemaPaneModel) isExpanded(rows []schemaRow) bool {
+	if model.cursor >= len(rows) {
+		return false
+	}
+
+	return model.expanded[rows[model.cursor].key]
+}
+
+func (model SchemaPaneModel) toggle(rows []schemaRow, expanded bool) SchemaPaneModel {
+	if model.cursor >= len(rows) || rows[model.cursor].key == "" {
+		return model
+	}
+
+	model.expanded = maps.Clone(model.expanded)
+	model.expanded[rows[model.cursor].key] = expanded
+
+	return model.moveCursor(0)
+}
+
+func (model SchemaPaneModel) moveCursor(delta int) SchemaPaneModel {
+	numRows := len(model.rows())
+	model.cursor = max(min(model.cursor+delta, numRows-1), 0)
+
+	if model.cursor < model.offset {
+		model.offset = model.cursor
+	}
+
+	if model.height > 0 && model.cursor >= model.offset+model.height {
+		model.offset = model.cursor - model.height + 1
+	}
+
+	return model
+}
+
+func (model SchemaPaneModel) rows() []schemaRow {
+	var rows []schemaRow
+
+	for _, schema := range model.Catalog.Schemas {
+		key := schemaKey(schema.Name)
+		rows = append(rows, schemaRow{
+			key:   key,
+			depth: 0,
+			text:  expander(model.expanded[key]) + schema.Name,
+			table: nil,
+		})
+
+		if !model.expanded[key] {
+			continue
+		}
+
+		for i := range schema.Tables {
+			table := &schema.Tables[i]
+			key := tableKey(*table)
+			rows = append(rows, schemaRow{
+				key:   key,
+				depth: 1,
+				text:  fmt.Sprintf("%s%s %s", expander(model.expanded[key]), table.Name, table.Kind),
+				table: table,
+			})
+
+			if model.expanded[key] {
+				rows = append(rows, tableRows(*table)...)
+			}
+		}
+	}
+
+	return rows
+}
+
+func tableRows(table db.Table) []schemaRow {
+	rows := make([]schemaRow, 0, len(table.Columns)+len(table.Indexes)+len(table.ForeignKeys))
+
+	for _, column := range table.Columns {
+		text := column.Name + " " + column.Type
+		if !column.Nullable {
+			text += " not null"
+		}
+
+		rows = append(rows, schemaRow{key: "", depth: 2, text: text, table: nil}) //nolint:mnd
+	}
+
+	for _, index := range table.Indexes {
+		definition := index.Definition
+		if _, using, ok := strings.Cut(definition, " USING "); ok {
+			definition = using
+		}
+
+		rows = append(rows, schemaRow{
+			key:   "",
+			depth: 2, //nolint:mnd
+			text:  strings.TrimSpace("index " + index.Name + " " + definition),
+			table: nil,
+		})
+	}
+
+	for _, foreignKey := range table.ForeignKeys {
+		rows = append(rows, schemaRow{
+			key:   "",
+			depth: 2, //nolint:mnd
+			text:  "fk " + strings.TrimPrefix(foreignKey.Definition, "FOREIGN KEY "),
+			table: nil,
+		})
+	}
+
+	return rows
+}
+
+func schemaKey(name string) string {
+	return "schema:" + name
+}
+
+func tableKey(table db.Table) string {
+	return "table:" + table.Schema + "." + table.Name
+}
+
+func expander(expanded bool) string {
+	if expanded {
+		return "▾ "
+	}
+
+	return "▸ "
+}
+
+func selectStatement(table db.Table) string {
+	return fmt.Sprintf("SELECT * FROM %s LIMIT %d;", table.QualifiedName(), selectLimit)
+}
+
+func (model SchemaPaneModel) SetSize(width int, height int) SchemaPaneModel {
+	model.width = width
+	model.height = height
+
+	return model.moveCursor(0)
+}
+
+func (model SchemaPaneModel) Focused() bool {
+	return model.focused
+}
+
+func (model SchemaPaneModel) Focus() SchemaPaneModel {
+	model.focused = true
+
+	return model
+}
+
+func (model SchemaPaneModel) Blur() SchemaPaneModel {
+	model.focused = false
+
+	return model
+}
+
+func (model SchemaPaneModel) View() string {
+	style := lipgloss.NewStyle().Width(model.width).Height(model.height)
+
+	if model.Err != nil {
+		return style.Render(ansi.Wrap(model.Err.Error(), model.width, ""))
+	}
+
+	rows := model.rows()
+	if len(rows) == 0 {
+		return style.Render("no tables")
+	}
+
+	end := len(rows)
+	if model.height > 0 {
+		end = min(end, model.offset+model.height)
+	}
+
+	lines := make([]string, 0, end-model.offset)
+	cursorStyle := lipgloss.NewStyle().Reverse(true)
+
+	for i := model.offset; i < end; i++ {
+		line := ansi.Truncate(strings.Repeat("  ", rows[i].depth)+rows[i].text, model.width-1, "…")
+		if i == model.cursor && model.focused {
+			line = cursorStyle.Render(line)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return style.Render(strings.Join(lines, "\n"))
+}
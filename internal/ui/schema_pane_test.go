@@ -0,0 +1,142 @@
+package ui_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jshawl/dbq/internal/db"
+	"github.com/jshawl/dbq/internal/testutil"
+	"github.com/jshawl/dbq/internal/ui"
+)
+
+const usersOID = 16384
+
+func makeCatalog() db.Catalog {
+	return db.Catalog{Schemas: []db.Schema{
+		{Name: "public", Tables: []db.Table{
+			{
+				Schema: "public",
+				Name:   "users",
+				OID:    usersOID,
+				Kind:   db.TableKindTable,
+				Columns: []db.TableColumn{
+					{Name: "id", Type: "integer", Nullable: false, AttributeNumber: 1},
+					{Name: "email", Type: "text", Nullable: true, AttributeNumber: 2},
+				},
+				Indexes: []db.Index{
+					{Name: "users_pkey", Definition: "CREATE UNIQUE INDEX users_pkey ON public.users USING btree (id)"},
+				},
+				ForeignKeys: nil,
+				PrimaryKey:  []string{"id"},
+			},
+		}},
+		{Name: "audit", Tables: []db.Table{
+			{Schema: "audit", Name: "User", Kind: db.TableKindView, Columns: nil, Indexes: nil, ForeignKeys: nil, PrimaryKey: nil},
+		}},
+	}, Functions: []string{"count", "now"}}
+}
+
+func setupSchemaPane(t *testing.T) ui.SchemaPaneModel {
+	t.Helper()
+
+	model := ui.NewSchemaPaneModel().Focus().SetSize(36, 20)
+	model, _ = model.Update(ui.CatalogMsg{Catalog: makeCatalog(), Err: nil})
+
+	return model
+}
+
+func TestSchemaPane_Update(t *testing.T) {
+	t.Parallel()
+
+	t.Run("enter on a table inserts a select", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupSchemaPane(t)
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('j'))
+		_, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		msg := testutil.AssertMsgType[ui.InsertQueryMsg](t, cmd)
+		if msg.Value != "SELECT * FROM users LIMIT 100;" {
+			t.Fatalf("unexpected query %q", msg.Value)
+		}
+	})
+
+	t.Run("enter qualifies and quotes names outside public", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupSchemaPane(t)
+		for range 3 {
+			model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyDown))
+		}
+
+		_, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		msg := testutil.AssertMsgType[ui.InsertQueryMsg](t, cmd)
+		if msg.Value != `SELECT * FROM audit."User" LIMIT 100;` {
+			t.Fatalf("unexpected query %q", msg.Value)
+		}
+	})
+
+	t.Run("expand a table", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupSchemaPane(t)
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('j'))
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('l'))
+
+		view := model.View()
+		for _, want := range []string{"id integer not null", "email text", "index users_pkey btree (id)"} {
+			if !strings.Contains(view, want) {
+				t.Fatalf("expected %q in view:\n%s", want, view)
+			}
+		}
+
+		model, _ = model.Update(testutil.MakeRuneKeyMsg('h'))
+		if strings.Contains(model.View(), "email text") {
+			t.Fatal("expected table to collapse")
+		}
+	})
+
+	t.Run("collapse a schema", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupSchemaPane(t)
+		model, _ = model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		if strings.Contains(model.View(), "users") {
+			t.Fatal("expected schema to collapse")
+		}
+	})
+
+	t.Run("r refreshes", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupSchemaPane(t)
+		_, cmd := model.Update(testutil.MakeRuneKeyMsg('r'))
+
+		testutil.AssertMsgType[ui.RefreshCatalogMsg](t, cmd)
+	})
+
+	t.Run("unfocused", func(t *testing.T) {
+		t.Parallel()
+
+		model := setupSchemaPane(t).Blur()
+
+		_, cmd := model.Update(testutil.MakeRuneKeyMsg('r'))
+		if cmd != nil {
+			t.Fatal("expected cmd to be nil")
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewSchemaPaneModel()
+		model, _ = model.Update(ui.CatalogMsg{Catalog: db.Catalog{Schemas: nil, Functions: nil}, Err: db.ErrCatalogUnsupported})
+
+		if !strings.Contains(model.View(), "does not support") {
+			t.Fatalf("expected error in view, got %q", model.View())
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/jshawl/dbq/internal/snippets"
+)
+
+// SnippetPickerModel filters the saved snippets to pick one for the query
+// pane.
+type SnippetPickerModel struct {
+	snippets []snippets.Snippet
+	matches  []snippets.Snippet
+	input    textinput.Model
+	err      error
+	cursor   int
+	visible  bool
+	width    int
+	height   int
+}
+
+// SnippetsMsg carries the saved snippets for the picker to filter.
+type SnippetsMsg struct {
+	Snippets []snippets.Snippet
+	Err      error
+}
+
+// SaveSnippetMsg asks the ui to save Query as a snippet called Name,
+// replacing one already named so only when Overwrite is true.
+type SaveSnippetMsg struct {
+	Name      string
+	Query     string
+	Overwrite bool
+}
+
+// SnippetSavedMsg reports a finished SaveSnippetMsg.
+type SnippetSavedMsg struct {
+	Name  string
+	Query string
+	Err   error
+}
+
+func NewSnippetPickerModel() SnippetPickerModel {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Cursor.SetMode(1)
+
+	return SnippetPickerModel{
+		snippets: nil,
+		matches:  nil,
+		input:    input,
+		err:      nil,
+		cursor:   0,
+		visible:  false,
+		width:    0,
+		height:   0,
+	}
+}
+
+func (model SnippetPickerModel) Update(msg tea.Msg) (SnippetPickerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case SnippetsMsg:
+		model.snippets = msg.Snippets
+		model.err = msg.Err
+
+		return model.filter(), nil
+	case tea.KeyMsg:
+		if !model.visible {
+			return model, nil
+		}
+
+		switch msg.String() {
+		case "up", "ctrl+p":
+			model.cursor = max(model.cursor-1, 0)
+
+			return model, nil
+		case "down", "ctrl+n":
+			model.cursor = max(min(model.cursor+1, len(model.matches)-1), 0)
+
+			return model, nil
+		case "esc", "ctrl+g":
+			model.visible = false
+
+			return model, nil
+		case "enter":
+			if len(model.matches) == 0 {
+				return model, nil
+			}
+
+			model.visible = false
+
+			return model, dispatch(InsertQueryMsg{
+				Value: strings.TrimSpace(model.matches[model.cursor].Query),
+			})
+		}
+
+		var cmd tea.Cmd
+
+		model.input, cmd = model.input.Update(msg)
+
+		return model.filter(), cmd
+	}
+
+	return model, nil
+}
+
+// filter keeps the snippets whose name or query contains every word
+// typed, ignoring case.
+func (model SnippetPickerModel) filter() SnippetPickerModel {
+	words := strings.Fields(strings.ToLower(model.input.Value()))
+	model.matches = nil
+	model.cursor = 0
+
+	for _, snippet := range model.snippets {
+		text := strings.ToLower(snippet.Name + " " + snippet.Query)
+		matched := true
+
+		for _, word := range words {
+			matched = matched && strings.Contains(text, word)
+		}
+
+		if matched {
+			model.matches = append(model.matches, snippet)
+		}
+	}
+
+	return model
+}
+
+// Show opens the picker with an empty filter, waiting on the snippets.
+func (model SnippetPickerModel) Show() SnippetPickerModel {
+	model.visible = true
+	model.snippets = nil
+	model.matches = nil
+	model.err = nil
+	model.cursor = 0
+	model.input.SetValue("")
+	model.input.Focus()
+
+	return model
+}
+
+func (model SnippetPickerModel) Visible() bool {
+	return model.visible
+}
+
+func (model SnippetPickerModel) SetSize(width int, height int) SnippetPickerModel {
+	model.width = width
+	model.height = height
+
+	return model
+}
+
+func (model SnippetPickerModel) View() string {
+	lines := []string{
+		"snippets (enter to insert, alt+s in the query pane to save one, esc to close)",
+		model.input.View(),
+		"",
+	}
+
+	if model.err != nil {
+		return strings.Join(append(lines, model.err.Error()), "\n")
+	}
+
+	if len(model.snippets) == 0 {
+		return strings.Join(append(lines, "no snippets in ~/.dbq/snippets"), "\n")
+	}
+
+	if len(model.matches) == 0 {
+		return strings.Join(append(lines, "no matching snippets"), "\n")
+	}
+
+	// as many as fit below the heading
+	shown := len(model.matches)
+	if model.height > 0 {
+		shown = max(model.height-len(lines), 1)
+	}
+
+	start := max(0, min(model.cursor-shown/2, len(model.matches)-shown))
+	end := min(start+shown, len(model.matches))
+	selected := lipgloss.NewStyle().Reverse(true)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888"))
+
+	nameWidth := 0
+	for _, snippet := range model.matches[start:end] {
+		nameWidth = max(nameWidth, ansi.StringWidth(snippet.Name))
+	}
+
+	for i := start; i < end; i++ {
+		snippet := model.matches[i]
+		name := snippet.Name + strings.Repeat(" ", nameWidth-ansi.StringWidth(snippet.Name))
+		query := strings.Join(strings.Fields(snippet.Query), " ")
+
+		if model.width > 0 {
+			query = ansi.Truncate(query, max(model.width-nameWidth-4, 1), "…")
+		}
+
+		if i == model.cursor {
+			name = selected.Render(name)
+		}
+
+		lines = append(lines, "  "+name+"  "+dim.Render(query))
+	}
+
+	return strings.Join(lines, "\n")
+}
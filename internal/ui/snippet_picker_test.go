@@ -0,0 +1,79 @@
+package ui_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jshawl/dbq/internal/snippets"
+	"github.com/jshawl/dbq/internal/testutil"
+	"github.com/jshawl/dbq/internal/ui"
+)
+
+func makeSnippets() []snippets.Snippet {
+	return []snippets.Snippet{
+		{Name: "active users", Query: "select *\nfrom users where active\n"},
+		{Name: "orders", Query: "select * from orders where user_id = :user_id\n"},
+	}
+}
+
+func TestSnippetPicker_Update(t *testing.T) {
+	t.Parallel()
+
+	t.Run("typing filters by name or query and enter inserts", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewSnippetPickerModel().Show()
+		model, _ = model.Update(ui.SnippetsMsg{Snippets: makeSnippets(), Err: nil})
+
+		for _, char := range "user_id" {
+			model, _ = model.Update(testutil.MakeRuneKeyMsg(char))
+		}
+
+		view := model.View()
+		if strings.Contains(view, "active users") || !strings.Contains(view, "orders") {
+			t.Fatalf("expected only the orders snippet, got\n%s", view)
+		}
+
+		model, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEnter))
+
+		msg := testutil.AssertMsgType[ui.InsertQueryMsg](t, cmd)
+		if msg.Value != "select * from orders where user_id = :user_id" {
+			t.Fatalf("expected the snippet's query, got %q", msg.Value)
+		}
+
+		if model.Visible() {
+			t.Fatal("expected the picker to close")
+		}
+	})
+
+	t.Run("esc closes", func(t *testing.T) {
+		t.Parallel()
+
+		model := ui.NewSnippetPickerModel().Show()
+		model, cmd := model.Update(testutil.MakeKeyMsg(tea.KeyEsc))
+
+		if cmd != nil || model.Visible() {
+			t.Fatal("expected esc to close without inserting")
+		}
+	})
+}
+
+func TestSnippetPicker_View(t *testing.T) {
+	t.Parallel()
+
+	model := ui.NewSnippetPickerModel().Show()
+	model, _ = model.Update(ui.SnippetsMsg{Snippets: nil, Err: nil})
+
+	if !strings.Contains(model.View(), "no snippets in ~/.dbq/snippets") {
+		t.Fatalf("expected no snippets, got\n%s", model.View())
+	}
+
+	model, _ = model.Update(ui.SnippetsMsg{Snippets: makeSnippets(), Err: nil})
+
+	view := model.View()
+	if !strings.Contains(view, "active users  select * from users where active") ||
+		!strings.Contains(view, "orders        select * from orders") {
+		t.Fatalf("expected snippets on one line with aligned names, got\n%s", view)
+	}
+}
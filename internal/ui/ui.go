@@ -2,15 +2,22 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"os"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jshawl/dbq/internal/clipboard"
+	"github.com/jshawl/dbq/internal/config"
 	"github.com/jshawl/dbq/internal/db"
+	"github.com/jshawl/dbq/internal/history"
 	"github.com/jshawl/dbq/internal/searchableviewport"
+	"github.com/jshawl/dbq/internal/snippets"
 )
 
 type Model struct {
@@ -19,38 +26,110 @@ type Model struct {
 	DB          *db.DB
 	ResultsPane ResultsPaneModel
 	QueryPane   QueryPaneModel
-
-	databaseUrl string
+	SchemaPane  SchemaPaneModel
+
+	ConnectionPicker ConnectionPickerModel
+	HistorySearch    HistorySearchModel
+	SnippetPicker    SnippetPickerModel
+	Form             FormModel
+
+	MaxRows  int
+	ReadOnly bool
+
+	profile           config.Profile
+	snippets          snippets.Store
+	placeholderValues map[string]string
+	cancelQuery       context.CancelFunc
+	// rows are the last statement's, left open while more may be read.
+	rows     *db.Rows
+	fetching bool
+	// pendingConnect waits for the canceled query to give up the connection.
+	pendingConnect *ConnectMsg
+	txStatus       db.TxStatus
+	quitWarned     bool
+	schemaVisible  bool
+	width          int
+	height         int
 }
 
+type pane int
+
+const (
+	queryPane pane = iota
+	resultsPane
+	schemaPane
+)
+
 type DBMsg struct {
-	DB *db.DB
+	DB  *db.DB
+	Err error
 }
 
 type QueryMsg struct {
-	Duration time.Duration
-	Err      error
-	Results  db.QueryResult
-	Query    string
+	Duration   time.Duration
+	Err        error
+	Results    db.QueryResult
+	Query      string
+	Params     db.Params
+	Statements []db.DBQueryResult
+	TxStatus   db.TxStatus
+
+	rows *db.Rows
+}
+
+type FetchRowsMsg struct{}
+
+// RowsMsg delivers a batch of the last statement's rows. Truncated rows
+// stopped at the row cap or were abandoned with rows left unread.
+type RowsMsg struct {
+	Rows       [][]interface{}
+	Done       bool
+	Truncated  bool
+	CommandTag string
+	Duration   time.Duration
+	Err        error
+	TxStatus   db.TxStatus
+}
+
+type TxEndedMsg struct {
+	CommandTag string
+	Err        error
+	TxStatus   db.TxStatus
+}
+
+type PlaceholdersFilledMsg struct {
+	Values db.Params
+	Next   tea.Msg
 }
 
+type paramsLoadedMsg struct {
+	placeholders []string
+	values       db.Params
+	next         func(params db.Params) tea.Msg
+}
+
+type ExplainedMsg struct {
+	Plan db.Plan
+	Err  error
+}
+
+const fetchBatchSize = 200
+
 type QueryResponseReceivedMsg struct {
 	QueryMsg
 }
 
-func Run() {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		log.Fatal("DATABASE_URL unset")
-	}
+type QueryStartedMsg struct {
+	StartedAt time.Time
+}
 
-	homeDir, err := os.UserHomeDir()
+// Run starts the ui connected to the named profile, or to DATABASE_URL.
+func Run(name string, readOnly bool) {
+	configPath, err := config.Dir()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	configPath := homeDir + "/.dbq"
-
 	const configPathPerms = 0o750
 
 	err = os.MkdirAll(configPath, configPathPerms)
@@ -58,6 +137,24 @@ func Run() {
 		log.Fatal(err)
 	}
 
+	cfg, err := config.Load(config.Path(configPath))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	profile, err := cfg.Resolve(name, os.Getenv("DATABASE_URL"))
+	if err != nil && (!errors.Is(err, config.ErrNoConnection) || len(cfg.Profiles) == 0) {
+		log.Fatal(err)
+	}
+
+	profile.ReadOnly = profile.ReadOnly || readOnly
+	model := NewUIModel(profile, cfg.List(), configPath)
+	model.MaxRows = cfg.RowLimit()
+	model.ReadOnly = readOnly
+	if profile.DSN == "" {
+		model.ConnectionPicker = model.ConnectionPicker.Show("")
+	}
+
 	f, _ := tea.LogToFile("debug.log", "debug")
 
 	defer func() {
@@ -68,7 +165,7 @@ func Run() {
 	}()
 
 	program := tea.NewProgram(
-		NewUIModel(os.Getenv("DATABASE_URL"), configPath),
+		model,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
@@ -83,45 +180,233 @@ func Run() {
 	}
 }
 
-func NewUIModel(databaseUrl string, configPath string) Model {
+func NewUIModel(profile config.Profile, profiles []config.Profile, configPath string) Model {
+	queryPane := NewQueryPaneModel(configPath)
+	queryPane.History = queryPane.History.SetProfile(profile.Name)
+
 	return Model{
 		DB:          nil,
 		Err:         nil,
-		Results:     db.QueryResult{},
+		Results:     db.QueryResult{Columns: nil, Rows: nil, CommandTag: ""},
 		ResultsPane: NewResultsPaneModel(),
-		QueryPane:   NewQueryPaneModel(configPath),
-
-		databaseUrl: databaseUrl,
+		QueryPane:   queryPane,
+		SchemaPane:  NewSchemaPaneModel(),
+
+		ConnectionPicker: NewConnectionPickerModel(profiles),
+		HistorySearch:    NewHistorySearchModel(),
+		SnippetPicker:    NewSnippetPickerModel(),
+		Form:             NewFormModel(),
+
+		MaxRows:  config.DefaultMaxRows,
+		ReadOnly: false,
+
+		profile:           profile,
+		snippets:          snippets.NewStore(snippets.Dir(configPath)),
+		placeholderValues: map[string]string{},
+		cancelQuery:       nil,
+		rows:              nil,
+		fetching:          false,
+		pendingConnect:    nil,
+		txStatus:          db.TxIdle,
+		quitWarned:        false,
+		schemaVisible:     false,
+		width:             0,
+		height:            0,
 	}
 }
 
 func (m Model) Init() tea.Cmd {
+	if m.profile.DSN == "" {
+		return nil
+	}
+
+	return connect(m.profile, nil)
+}
+
+func connect(profile config.Profile, previous *db.DB) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		pgdb, _ := db.NewPostgresDB(ctx, m.databaseUrl)
-		db := db.NewDB(pgdb)
+
+		if previous != nil {
+			err := previous.Close(ctx)
+			if err != nil {
+				log.Println(err)
+			}
+		}
+
+		queryable, err := db.Open(ctx, profile.DSN, db.Options{
+			Schema:   profile.Schema,
+			ReadOnly: profile.ReadOnly,
+		})
+		if err != nil {
+			return DBMsg{
+				DB:  nil,
+				Err: err,
+			}
+		}
 
 		return DBMsg{
-			DB: db,
+			DB:  db.NewDB(queryable),
+			Err: nil,
 		}
 	}
 }
 
-func query(sql string, db *db.DB) tea.Cmd {
+func query(
+	ctx context.Context,
+	sql string,
+	params db.Params,
+	database *db.DB,
+	maxRows int,
+) tea.Cmd {
 	return func() tea.Msg {
-		ctx := context.Background()
+		statements, rows := database.StreamStatements(ctx, sql, params, fetchBatchSize, maxRows)
+		last := statements[len(statements)-1]
+
+		var duration time.Duration
+		for _, statement := range statements {
+			duration += statement.Duration
+		}
 
-		results := db.Query(ctx, sql)
+		var txStatus db.TxStatus
+		if rows != nil {
+			txStatus = rows.TxStatus()
+		} else {
+			txStatus = database.TxStatus(context.Background())
+		}
 
 		return QueryMsg{
-			Err:      results.Err,
-			Results:  results.Results,
-			Duration: results.Duration,
-			Query:    sql,
+			Err:        last.Err,
+			Results:    last.Results,
+			Duration:   duration,
+			Query:      sql,
+			Params:     params,
+			Statements: statements,
+			TxStatus:   txStatus,
+			rows:       rows,
 		}
 	}
 }
 
+func fetchRows(ctx context.Context, database *db.DB, rows *db.Rows) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		batch, err := rows.Next(ctx, fetchBatchSize)
+		duration := time.Since(start)
+
+		txStatus := rows.TxStatus()
+		if rows.Done() {
+			txStatus = database.TxStatus(context.Background())
+		}
+
+		return RowsMsg{
+			Rows:       batch,
+			Done:       rows.Done(),
+			Truncated:  rows.Truncated(),
+			CommandTag: rows.CommandTag(),
+			Duration:   duration,
+			Err:        err,
+			TxStatus:   txStatus,
+		}
+	}
+}
+
+func closeRows(rows *db.Rows) {
+	if rows == nil {
+		return
+	}
+
+	err := rows.Close()
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func loadCatalog(database *db.DB) tea.Cmd {
+	return func() tea.Msg {
+		catalog, err := database.Catalog(context.Background())
+
+		return CatalogMsg{Catalog: catalog, Err: err}
+	}
+}
+
+func endTransaction(ctx context.Context, database *db.DB, statement string) tea.Cmd {
+	return func() tea.Msg {
+		result := database.Query(ctx, statement)
+
+		return TxEndedMsg{
+			CommandTag: result.Results.CommandTag,
+			Err:        result.Err,
+			TxStatus:   database.TxStatus(context.Background()),
+		}
+	}
+}
+
+func applyUpdates(ctx context.Context, database *db.DB, msg CommitEditsMsg) tea.Cmd {
+	return func() tea.Msg {
+		return EditsCommittedMsg{
+			Err:   database.ApplyUpdates(ctx, msg.Statements),
+			edits: msg.edits,
+		}
+	}
+}
+
+func explain(ctx context.Context, database *db.DB, msg ExplainMsg) tea.Cmd {
+	return func() tea.Msg {
+		plan, err := database.Explain(ctx, msg.Value, msg.Analyze, msg.Params)
+
+		return ExplainedMsg{Plan: plan, Err: err}
+	}
+}
+
+func loadHistory(model history.Model) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := model.Entries(historySearchLimit)
+
+		return HistoryEntriesMsg{Entries: entries, Err: err}
+	}
+}
+
+func loadParams(
+	model history.Model,
+	query string,
+	placeholders []string,
+	next func(params db.Params) tea.Msg,
+) tea.Cmd {
+	return func() tea.Msg {
+		values, err := model.LastParams(query)
+		if err != nil {
+			log.Println(err)
+		}
+
+		return paramsLoadedMsg{placeholders: placeholders, values: values, next: next}
+	}
+}
+
+func loadSnippets(store snippets.Store) tea.Cmd {
+	return func() tea.Msg {
+		list, err := store.List()
+
+		return SnippetsMsg{Snippets: list, Err: err}
+	}
+}
+
+func saveSnippet(store snippets.Store, msg SaveSnippetMsg) tea.Cmd {
+	return func() tea.Msg {
+		return SnippetSavedMsg{
+			Name:  msg.Name,
+			Query: msg.Query,
+			Err:   store.Save(msg.Name, msg.Query, msg.Overwrite),
+		}
+	}
+}
+
+func copyToClipboard(msg CopyMsg) tea.Cmd {
+	return func() tea.Msg {
+		return CopiedMsg{Description: msg.Description, Err: clipboard.Write(msg.Text)}
+	}
+}
+
 func dispatch(msg tea.Msg) tea.Cmd {
 	return func() tea.Msg {
 		return msg
@@ -129,39 +414,284 @@ func dispatch(msg tea.Msg) tea.Cmd {
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.update(msg)
+
+	if m.pendingConnect == nil || m.fetching {
+		return m, cmd
+	}
+
+	pending := *m.pendingConnect
+	m.pendingConnect = nil
+
+	return m, tea.Batch(cmd, dispatch(pending))
+}
+
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
 	//nolint:exhaustive
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.Form.Visible() && msg.Type != tea.KeyCtrlC {
+			var cmd tea.Cmd
+
+			m.Form, cmd = m.Form.Update(msg)
+
+			return m, cmd
+		}
+
+		if m.ConnectionPicker.Visible() && msg.Type != tea.KeyCtrlC {
+			var cmd tea.Cmd
+
+			m.ConnectionPicker, cmd = m.ConnectionPicker.Update(msg)
+
+			return m, cmd
+		}
+
+		if m.HistorySearch.Visible() && msg.Type != tea.KeyCtrlC {
+			var cmd tea.Cmd
+
+			m.HistorySearch, cmd = m.HistorySearch.Update(msg)
+
+			return m, cmd
+		}
+
+		if m.SnippetPicker.Visible() && msg.Type != tea.KeyCtrlC {
+			var cmd tea.Cmd
+
+			m.SnippetPicker, cmd = m.SnippetPicker.Update(msg)
+
+			return m, cmd
+		}
+
+		if msg.Type != tea.KeyCtrlC {
+			m.quitWarned = false
+		}
+
+		switch msg.String() {
+		case "alt+m":
+			return m.endTransaction("COMMIT")
+		case "alt+r":
+			return m.endTransaction("ROLLBACK")
+		case "alt+h":
+			return m.toggleGlobalHistory()
+		case "alt+s":
+			return m.promptSnippetName(), nil
+		}
+
 		switch msg.Type {
 		case tea.KeyTab:
 			return m.cycleFocus(), nil
-		case tea.KeyCtrlC:
-			m.QueryPane.History.Cleanup()
+		case tea.KeyCtrlO:
+			return m.toggleSchemaPane()
+		case tea.KeyF5:
+			return m, dispatch(RefreshCatalogMsg{})
+		case tea.KeyCtrlG:
+			m.ConnectionPicker = m.ConnectionPicker.Show(m.profile.Name)
+
+			return m, nil
+		case tea.KeyCtrlR:
+			m.HistorySearch = m.HistorySearch.Show()
+
+			return m, loadHistory(m.QueryPane.History)
+		case tea.KeyCtrlT:
+			m.SnippetPicker = m.SnippetPicker.Show()
+
+			return m, loadSnippets(m.snippets)
+		case tea.KeyCtrlC, tea.KeyEscape:
+			if m.fetching {
+				m.cancelQuery()
+
+				return m, nil
+			}
 
-			return m, tea.Quit
+			if msg.Type == tea.KeyCtrlC {
+				// closing the connection rolls the transaction back
+				if m.txStatus != db.TxIdle && !m.quitWarned {
+					m.quitWarned = true
+
+					return m, nil
+				}
+
+				m.QueryPane.History.Cleanup()
+
+				return m, tea.Quit
+			}
 		}
 	case tea.WindowSizeMsg:
-		return m, dispatch(searchableviewport.WindowSizeMsg{
-			Width:  msg.Width,
-			Height: msg.Height - lipgloss.Height(m.QueryPane.View()),
-		})
+		m.width = msg.Width
+		m.height = msg.Height
+
+		return m.resize()
 	case QueryExecMsg:
-		return m, query(msg.Value, m.DB)
+		if m.DB == nil || m.fetching {
+			return m, nil
+		}
+
+		placeholders := db.Placeholders(msg.Value)
+		if len(placeholders) > 0 && msg.Params == nil {
+			next := func(params db.Params) tea.Msg {
+				return QueryExecMsg{Value: msg.Value, Params: params}
+			}
+
+			return m, loadParams(m.QueryPane.History, msg.Value, placeholders, next)
+		}
+
+		return m.run(func(ctx context.Context) tea.Cmd {
+			return query(ctx, msg.Value, msg.Params, m.DB, m.MaxRows)
+		})
 	case QueryMsg:
+		m = m.doneFetching()
+		m.rows = msg.rows
+
+		if m.rows == nil {
+			m, _ = m.releaseRows()
+		}
+
 		if msg.Err == nil {
-			m.QueryPane = m.QueryPane.Blur()
-			m.ResultsPane = m.ResultsPane.Focus()
+			m = m.focus(resultsPane)
 		}
 
-		return m, dispatch(QueryResponseReceivedMsg{
+		m, cmd := m.setTxStatus(msg.TxStatus)
+
+		return m, tea.Batch(cmd, dispatch(QueryResponseReceivedMsg{
 			QueryMsg: msg,
-		})
+		}))
 	case DBMsg:
 		m.DB = msg.DB
+		m.Err = msg.Err
+
+		if m.DB == nil {
+			return m, nil
+		}
+
+		return m, loadCatalog(m.DB)
+	case FetchRowsMsg:
+		if m.rows == nil || m.fetching {
+			return m, nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		m.cancelQuery = cancel
+		m.fetching = true
+
+		return m, fetchRows(ctx, m.DB, m.rows)
+	case RowsMsg:
+		if m.rows == nil {
+			return m.updatePanes(msg)
+		}
+
+		m = m.doneFetching()
+		m, cmd := m.setTxStatus(msg.TxStatus)
+
+		if msg.Done || msg.Err != nil {
+			m, _ = m.releaseRows()
+		}
+
+		m, paneCmd := m.updatePanes(msg)
+
+		return m, tea.Batch(cmd, paneCmd)
+	case ConnectMsg:
+		if m.fetching {
+			m.cancelQuery()
+			m.pendingConnect = &msg
+
+			return m, nil
+		}
+
+		m, _ = m.releaseRows()
+
+		previous := m.DB
+		m.DB = nil
+		m.Err = nil
+		m.profile = msg.Profile
+		m.profile.ReadOnly = m.profile.ReadOnly || m.ReadOnly
+		m.QueryPane.History = m.QueryPane.History.SetProfile(m.profile.Name)
+		m.txStatus = db.TxIdle
+		m.quitWarned = false
+
+		m, cmd := m.resize()
+
+		return m, tea.Batch(cmd, connect(m.profile, previous))
+	case RefreshCatalogMsg:
+		if m.DB == nil || m.fetching {
+			return m, nil
+		}
+
+		return m.afterRows(loadCatalog(m.DB))
+	case CommitEditsMsg:
+		if m.DB == nil || m.fetching {
+			return m, nil
+		}
+
+		return m.run(func(ctx context.Context) tea.Cmd {
+			return applyUpdates(ctx, m.DB, msg)
+		})
+	case EditsCommittedMsg:
+		m = m.doneFetching()
+		m, _ = m.releaseRows()
+	case ExplainMsg:
+		if m.DB == nil || m.fetching {
+			return m, nil
+		}
+
+		placeholders := db.Placeholders(msg.Value)
+		if len(placeholders) > 0 && msg.Params == nil {
+			next := func(params db.Params) tea.Msg {
+				return ExplainMsg{Value: msg.Value, Analyze: msg.Analyze, Params: params}
+			}
+
+			return m, loadParams(m.QueryPane.History, msg.Value, placeholders, next)
+		}
+
+		return m.run(func(ctx context.Context) tea.Cmd {
+			return explain(ctx, m.DB, msg)
+		})
+	case ExplainedMsg:
+		m = m.doneFetching()
+
+		if msg.Err == nil {
+			m = m.focus(resultsPane)
+		}
+	case TxEndedMsg:
+		m = m.doneFetching()
+		m, _ = m.releaseRows()
+
+		m, cmd := m.setTxStatus(msg.TxStatus)
+		m, paneCmd := m.updatePanes(msg)
+
+		return m, tea.Batch(cmd, paneCmd)
+	case HistoryEntriesMsg:
+		var cmd tea.Cmd
+
+		m.HistorySearch, cmd = m.HistorySearch.Update(msg)
+
+		return m, cmd
+	case paramsLoadedMsg:
+		return m.promptPlaceholders(msg), nil
+	case PlaceholdersFilledMsg:
+		m.placeholderValues = maps.Clone(m.placeholderValues)
+		maps.Copy(m.placeholderValues, msg.Values)
+
+		return m, dispatch(msg.Next)
+	case SnippetsMsg:
+		m.SnippetPicker, _ = m.SnippetPicker.Update(msg)
 
 		return m, nil
+	case SaveSnippetMsg:
+		return m, saveSnippet(m.snippets, msg)
+	case SnippetSavedMsg:
+		if errors.Is(msg.Err, snippets.ErrExists) {
+			return m.confirmSnippetOverwrite(msg), nil
+		}
+	case InsertQueryMsg:
+		return m.focus(queryPane), dispatch(history.SetInputValueMsg{Value: msg.Value})
+	case CopyMsg:
+		return m, copyToClipboard(msg)
 	}
 
+	return m.updatePanes(msg)
+}
+
+func (m Model) updatePanes(msg tea.Msg) (Model, tea.Cmd) {
 	var (
 		cmd  tea.Cmd
 		cmds []tea.Cmd
@@ -170,35 +700,307 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.ResultsPane, cmd = m.ResultsPane.Update(msg)
 	cmds = append(cmds, cmd)
 
+	queryPaneHeight := m.QueryPane.Height()
+
 	m.QueryPane, cmd = m.QueryPane.Update(msg)
 	cmds = append(cmds, cmd)
 
+	m.SchemaPane, cmd = m.SchemaPane.Update(msg)
+	cmds = append(cmds, cmd)
+
+	if m.QueryPane.Height() != queryPaneHeight {
+		m, cmd = m.resize()
+		cmds = append(cmds, cmd)
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
+func (m Model) endTransaction(statement string) (Model, tea.Cmd) {
+	if m.DB == nil || m.fetching || m.txStatus == db.TxIdle {
+		return m, nil
+	}
+
+	return m.run(func(ctx context.Context) tea.Cmd {
+		return endTransaction(ctx, m.DB, statement)
+	})
+}
+
+// run starts what start makes of a cancelable ctx as the running query.
+func (m Model) run(start func(ctx context.Context) tea.Cmd) (Model, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m, cmd := m.afterRows(start(ctx))
+	m.cancelQuery = cancel
+	m.fetching = true
+
+	return m, tea.Batch(cmd, dispatch(QueryStartedMsg{StartedAt: time.Now()}))
+}
+
+// afterRows runs cmd once the open rows holding the connection it needs
+// are closed, telling the panes no more of them will be read.
+func (m Model) afterRows(cmd tea.Cmd) (Model, tea.Cmd) {
+	m, previous := m.releaseRows()
+	if previous == nil {
+		return m, cmd
+	}
+
+	m, paneCmd := m.updatePanes(RowsMsg{
+		Rows:       nil,
+		Done:       true,
+		Truncated:  true,
+		CommandTag: "",
+		Duration:   0,
+		Err:        nil,
+		TxStatus:   db.TxIdle,
+	})
+
+	return m, tea.Batch(paneCmd, func() tea.Msg {
+		closeRows(previous)
+
+		return cmd()
+	})
+}
+
+func (m Model) setTxStatus(status db.TxStatus) (Model, tea.Cmd) {
+	height := m.statusHeight()
+	m.txStatus = status
+
+	if m.statusHeight() == height {
+		return m, nil
+	}
+
+	return m.resize()
+}
+
+func (m Model) promptSnippetName() Model {
+	query := m.QueryPane.TextArea.Value()
+	if strings.TrimSpace(query) == "" {
+		return m
+	}
+
+	m.Form = m.Form.Show(
+		"save snippet",
+		[]string{"name"},
+		nil,
+		func(values map[string]string) tea.Msg {
+			return SaveSnippetMsg{Name: values["name"], Query: query, Overwrite: false}
+		},
+	)
+
+	return m
+}
+
+func (m Model) confirmSnippetOverwrite(msg SnippetSavedMsg) Model {
+	m.Form = m.Form.Show(
+		"snippet "+msg.Name+" exists, enter to overwrite it or rename it",
+		[]string{"name"},
+		map[string]string{"name": msg.Name},
+		func(values map[string]string) tea.Msg {
+			return SaveSnippetMsg{
+				Name:      values["name"],
+				Query:     msg.Query,
+				Overwrite: values["name"] == msg.Name,
+			}
+		},
+	)
+
+	return m
+}
+
+func (m Model) promptPlaceholders(msg paramsLoadedMsg) Model {
+	values := maps.Clone(m.placeholderValues)
+	maps.Copy(values, msg.values)
+
+	m.Form = m.Form.Show(
+		"values to bind, empty for NULL",
+		msg.placeholders,
+		values,
+		func(values map[string]string) tea.Msg {
+			return PlaceholdersFilledMsg{Values: values, Next: msg.next(values)}
+		},
+	)
+
+	return m
+}
+
+func (m Model) toggleGlobalHistory() (Model, tea.Cmd) {
+	height := m.statusHeight()
+	m.QueryPane.History = m.QueryPane.History.SetGlobal(!m.QueryPane.History.Global())
+
+	if m.statusHeight() == height {
+		return m, nil
+	}
+
+	return m.resize()
+}
+
+// releaseRows hands back the open rows for closing off the ui's goroutine.
+func (m Model) releaseRows() (Model, *db.Rows) {
+	previous := m.rows
+	m.rows = nil
+
+	return m, previous
+}
+
+func (m Model) doneFetching() Model {
+	m.fetching = false
+
+	if m.cancelQuery != nil {
+		m.cancelQuery()
+		m.cancelQuery = nil
+	}
+
+	return m
+}
+
+func (m Model) resize() (Model, tea.Cmd) {
+	width := m.width
+	if m.schemaVisible {
+		width = max(width-schemaPaneWidth, 0)
+		m.SchemaPane = m.SchemaPane.SetSize(schemaPaneWidth, m.height)
+	}
+
+	m.QueryPane = m.QueryPane.SetWidth(width)
+	m.HistorySearch = m.HistorySearch.SetSize(m.width, m.height-m.statusHeight())
+	m.SnippetPicker = m.SnippetPicker.SetSize(m.width, m.height-m.statusHeight())
+
+	return m, dispatch(searchableviewport.WindowSizeMsg{
+		Width:  width,
+		Height: m.height - lipgloss.Height(m.QueryPane.View()) - m.statusHeight(),
+	})
+}
+
+func (m Model) toggleSchemaPane() (Model, tea.Cmd) {
+	m.schemaVisible = !m.schemaVisible
+
+	if m.schemaVisible {
+		m = m.focus(schemaPane)
+	} else if m.SchemaPane.Focused() {
+		m = m.focus(queryPane)
+	}
+
+	return m.resize()
+}
+
+func (m Model) statusView() string {
+	var parts []string
+
+	switch {
+	case m.profile.Name != "":
+		parts = append(parts, profileView(m.profile))
+	case m.profile.ReadOnly:
+		parts = append(parts, "(read-only)")
+	}
+
+	if m.profile.DSN == "" {
+		dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888"))
+		parts = append(parts, dim.Render("no connection (ctrl+g to pick one)"))
+	}
+
+	if view := m.txView(); view != "" {
+		parts = append(parts, view)
+	}
+
+	if m.QueryPane.History.Global() {
+		dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888"))
+		parts = append(parts, dim.Render("history from all connections (alt+h for this one's)"))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return strings.Join(parts, "  ") + "\n"
+}
+
+func (m Model) txView() string {
+	yellow := lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	red := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+	switch {
+	case m.txStatus == db.TxIdle:
+		return ""
+	case m.quitWarned:
+		return red.Render("transaction open: ctrl+c again to quit and roll it back")
+	case m.txStatus == db.TxFailed:
+		return red.Render("failed transaction (alt+r to roll back)")
+	}
+
+	return yellow.Render("in transaction (alt+m to commit, alt+r to roll back)")
+}
+
+func (m Model) statusHeight() int {
+	return strings.Count(m.statusView(), "\n")
+}
+
 func (m Model) View() string {
+	if m.Form.Visible() {
+		return m.statusView() + m.Form.View()
+	}
+
+	if m.ConnectionPicker.Visible() {
+		return m.statusView() + m.ConnectionPicker.View()
+	}
+
+	if m.HistorySearch.Visible() {
+		return m.statusView() + m.HistorySearch.View()
+	}
+
+	if m.SnippetPicker.Visible() {
+		return m.statusView() + m.SnippetPicker.View()
+	}
+
 	if m.Err != nil {
 		return fmt.Sprintf(
-			"%s\n%s",
+			"%s%s\n%s",
+			m.statusView(),
 			m.QueryPane.View(),
 			m.Err.Error(),
 		)
 	}
 
-	return fmt.Sprintf(
-		"%s\n%s",
+	view := fmt.Sprintf(
+		"%s%s\n%s",
+		m.statusView(),
 		withFocusView(m.QueryPane.View(), m.QueryPane.Focused()),
 		withFocusView(m.ResultsPane.View(), m.ResultsPane.Focused()),
 	)
+
+	if !m.schemaVisible {
+		return view
+	}
+
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		withFocusView(m.SchemaPane.View(), m.SchemaPane.Focused()),
+		view,
+	)
 }
 
 func (m Model) cycleFocus() Model {
-	if m.QueryPane.Focused() {
-		m.QueryPane = m.QueryPane.Blur()
-		m.ResultsPane = m.ResultsPane.Focus()
-	} else {
+	switch {
+	case m.QueryPane.Focused():
+		return m.focus(resultsPane)
+	case m.ResultsPane.Focused() && m.schemaVisible:
+		return m.focus(schemaPane)
+	default:
+		return m.focus(queryPane)
+	}
+}
+
+func (m Model) focus(target pane) Model {
+	m.QueryPane = m.QueryPane.Blur()
+	m.ResultsPane = m.ResultsPane.Blur()
+	m.SchemaPane = m.SchemaPane.Blur()
+
+	switch target {
+	case queryPane:
 		m.QueryPane = m.QueryPane.Focus()
-		m.ResultsPane = m.ResultsPane.Blur()
+	case resultsPane:
+		m.ResultsPane = m.ResultsPane.Focus()
+	case schemaPane:
+		m.SchemaPane = m.SchemaPane.Focus()
 	}
 
 	return m
@@ -1,9 +1,97 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/jshawl/dbq/internal/cli"
+	"github.com/jshawl/dbq/internal/config"
 	"github.com/jshawl/dbq/internal/ui"
 )
 
+// usageExitCode is what the flag package exits with on bad arguments.
+const usageExitCode = 2
+
 func main() {
-	ui.Run()
+	profile := flag.String("profile", "", "connection profile from ~/.dbq/config.yaml")
+	command := flag.String("c", "", "run SQL and print the results instead of starting the ui")
+	outputFormat := flag.String(
+		"format",
+		"table",
+		"output format for -c or stdin: table, csv, tsv, json, ndjson, markdown or sql",
+	)
+	readOnly := flag.Bool(
+		"read-only",
+		false,
+		"connect read-only, even when the profile does not ask to",
+	)
+	flag.Parse()
+
+	// flags may follow the profile too, like dbq staging -c "select 1"
+	if *profile == "" && flag.NArg() > 0 {
+		*profile = flag.Arg(0)
+
+		err := flag.CommandLine.Parse(flag.Args()[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if flag.NArg() > 0 {
+		fmt.Fprintln(flag.CommandLine.Output(), "unexpected arguments:", strings.Join(flag.Args(), " "))
+		flag.Usage()
+		os.Exit(usageExitCode)
+	}
+
+	sql := *command
+	if sql == "" && stdinIsPiped() {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		sql = string(input)
+	}
+
+	if sql == "" {
+		ui.Run(*profile, *readOnly)
+
+		return
+	}
+
+	configPath, err := config.Dir()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := cli.Run(ctx, cli.Options{
+		Profile:     *profile,
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+		ConfigPath:  config.Path(configPath),
+		SQL:         sql,
+		Format:      *outputFormat,
+		ReadOnly:    *readOnly,
+	}, os.Stdout, os.Stderr)
+
+	stop()
+	os.Exit(code)
+}
+
+// stdinIsPiped reports whether stdin is a pipe or a file to read SQL from,
+// rather than a terminal, /dev/null or a socket left open by whatever
+// started dbq, which reading would wait on forever.
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeNamedPipe != 0 || info.Mode().IsRegular() && info.Size() > 0
 }